@@ -0,0 +1,172 @@
+/*
+Pop up window with all fields of one bond and scrollable table of it cash flows, pushed to Navigator
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"bonds_payment_calendar/widget"
+	"fmt"
+
+	"github.com/gbin/goncurses"
+)
+
+var (
+	cashFlowColumns = []widget.Column{
+		{Title: "#", Right: true},
+		{Title: "Date"},
+		{Title: "Type"},
+		{Title: "Amount", Right: true},
+		{Title: "Days", Right: true},
+		{Title: "Status"},
+	}
+)
+
+type BondDetailsView struct {
+	Bond *bonds.BondsData
+
+	window *FSMWindow
+	fields []string      // Lines with fields
+	flows  *widget.Table // All cash flows of bond
+}
+
+func BondDetailsViewNew(obj *bonds.BondsData) *BondDetailsView {
+	view := new(BondDetailsView)
+	view.Bond = obj
+	flows := obj.CashFlows()
+	rows := make([][]string, 0, len(flows))
+
+	for ind, flow := range flows {
+		rows = append(rows, []string{
+			fmt.Sprint(ind + 1),
+			flow.Date.Format(DefaultDateLayout),
+			flow.Type,
+			fmt.Sprintf("%.2f", flow.Amount),
+			fmt.Sprint(flow.DaysUntil()),
+			CashFlowStatus(flows, ind),
+		})
+	}
+
+	view.flows = widget.TableNew(cashFlowColumns, rows, false)
+	view.fields = []string{fmt.Sprintf("id: %s", obj.ID)}
+
+	for _, field := range bonds.EditableFields {
+		value, _ := obj.FieldValue(field)
+		view.fields = append(view.fields, fmt.Sprintf("%s: %s", field, value))
+	}
+
+	view.fields = append(view.fields,
+		fmt.Sprintf("next pay date: %s", obj.NextPayDate().Format(DefaultDateLayout)),
+		fmt.Sprintf("maturity date: %s", obj.MaturityDate().Format(DefaultDateLayout)),
+		fmt.Sprintf("yield: %.2f%%", obj.Yield()),
+	)
+
+	return view
+}
+
+/*
+Status of cash flow: 'paid' for past, 'next' for nearest future payment date, 'scheduled' for others
+Past dates are removed from schedule by CalcAll, so only fully expired bond has paid flows
+*/
+func CashFlowStatus(flows []bonds.CashFlow, ind int) string {
+	if flows[ind].DaysUntil() < 0 {
+		return "paid"
+	}
+
+	for prev := 0; prev < ind; prev++ {
+		if flows[prev].DaysUntil() >= 0 && !flows[prev].Date.Equal(flows[ind].Date) {
+			return "scheduled"
+		}
+	}
+
+	return "next"
+}
+
+/* Fields printed in two columns, return lines of layout */
+func (self *BondDetailsView) fieldsLayout() []string {
+	var columnWidth int
+	half := (len(self.fields) + 1) / 2
+	result := make([]string, 0, half)
+
+	for _, field := range self.fields[:half] {
+		columnWidth = max(columnWidth, widget.Width(field)+2)
+	}
+
+	for ind := 0; ind < half; ind++ {
+		line := fmt.Sprintf("%-*s", columnWidth, self.fields[ind])
+
+		if ind+half < len(self.fields) {
+			line += self.fields[ind+half]
+		}
+
+		result = append(result, line)
+	}
+
+	return result
+}
+
+/* Area of cash flows table inside of window, under fields and line */
+func (self *BondDetailsView) tableArea() widget.Rect {
+	posY := len(self.fieldsLayout()) + 2
+	return widget.Rect{SizeY: max(self.window.SizeY-posY-1, 0), SizeX: self.window.SizeX - 2, PosY: posY, PosX: 1}
+}
+
+func (self *BondDetailsView) draw() {
+	win, sizeX := self.window.Window, self.window.SizeX
+	var y int = 1
+
+	for _, line := range self.fieldsLayout() {
+		win.MovePrint(y, 1, widget.Clip(line, sizeX-2))
+		y++
+	}
+
+	win.HLine(y, 1, goncurses.ACS_HLINE, sizeX-2)
+
+	if area := self.tableArea(); area.SizeY > 1 {
+		self.flows.Draw(win, area)
+	}
+}
+
+/*
+Create window centered at parent and push it to Navigator, window closed by ActionExit
+Keys and mouse wheel scroll table of cash flows
+*/
+func (self *BondDetailsView) Open(parent Rect) error {
+	title := fmt.Sprintf("|%s: %d payments|", self.Bond.Name, len(self.flows.Rows))
+	layout := self.fieldsLayout()
+	sizeY, sizeX := self.flows.Size(parent.SizeY-len(layout)-3, parent.SizeX-2)
+
+	for _, line := range layout {
+		sizeX = max(sizeX, widget.Width(line))
+	}
+
+	sizeX = max(sizeX, widget.Width(title))
+	rect := widget.Center(widget.Rect(parent), sizeY+len(layout)+3, sizeX+2)
+	window, err := FSMWindowNew(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+
+	if err != nil {
+		return err
+	}
+
+	self.window = window
+	closeIf := func(result widget.Result) bool {
+		if result == widget.ResultClose {
+			Navigator.Close(window)
+		}
+
+		return true
+	}
+
+	window.SetTitle(title).SetCustomDraw(self.draw).
+		RegisterDefaultInput(func(key goncurses.Key) bool {
+			return closeIf(self.flows.Input(WidgetCommand(key, self.flows), key))
+		}).
+		RegisterMouse(func(y, x int, button goncurses.MouseButton) bool {
+			wheel, clicks := WidgetMouse(button)
+			area := self.tableArea()
+			return closeIf(self.flows.Mouse(y-area.PosY, x-area.PosX, wheel, clicks))
+		})
+
+	Navigator.Push(window)
+	return nil
+}
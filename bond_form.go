@@ -0,0 +1,278 @@
+/*
+Full screen form for create new bond, pushed to Navigator
+All fields are shown at once, each field validated when focus leaves it,
+schedule of payments is calculated from current values and updated after each change
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"bonds_payment_calendar/widget"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	NextPayDateField      = "nextPayDate" // Not a bond field, period calculated from it and near pay date
+	bondFormPreviewWidth  = 40            // Minimal width of preview for place it at right of form
+	bondFormPreviewHeader = "Schedule preview"
+)
+
+var (
+	/* Fields of form in order of filling, hints are shown under form */
+	bondFormFields = []struct {
+		Name string
+		Hint string
+	}{
+		{"name", "Bond name, required"},
+		{"isin", "ISIN like RU000A0JX0J2, can be empty"},
+		{"couponCount", "Count of remaining coupons, at least 1"},
+		{"nearPayDate", "Nearest pay date [dd.mm.yyyy]"},
+		{NextPayDateField, "Next pay date [dd.mm.yyyy], can be empty for one coupon"},
+		{"couponValue", "Amount of one coupon for one bond, like 12.5"},
+		{"faceValue", "Amount returned at maturity for one bond, can be empty"},
+		{"price", "Purchase price of one bond for yield, can be empty"},
+		{"quantity", "Count of owned bonds"},
+		{"currency", "Currency of payments, like RUB"},
+		{"category", "One category, like risk bucket or account"},
+		{"tags", "Comma separated tags, like bank,ofz"},
+		{"notes", "Free-form notes"},
+	}
+
+	/* Fields which change schedule, errors of other fields do not hide preview */
+	scheduleFields = []string{"couponCount", "couponPeriod", "nearPayDate", "couponValue", "faceValue", "quantity"}
+)
+
+type BondFormView struct {
+	window  *FSMWindow
+	form    *widget.Form
+	preview *widget.Table
+
+	formArea    widget.Rect // Areas at last draw, for mouse
+	previewArea widget.Rect
+}
+
+func BondFormViewNew() *BondFormView {
+	view := new(BondFormView)
+	defaults := map[string]string{
+		"couponCount": "1",
+		"nearPayDate": time.Now().AddDate(0, 0, 1).Format(DefaultDateLayout),
+		"quantity":    "1",
+	}
+	fields := make([]*widget.Field, 0, len(bondFormFields))
+
+	for _, item := range bondFormFields {
+		fields = append(fields, &widget.Field{
+			Label:    item.Name,
+			Value:    defaults[item.Name],
+			Hint:     item.Hint,
+			Validate: view.validator(item.Name, item.Hint),
+		})
+	}
+
+	view.form = widget.FormNew(fields...)
+	view.form.OnChange = func(form *widget.Form) {
+		view.updatePreview()
+	}
+	view.form.OnSubmit = func(form *widget.Form) error {
+		return view.submit()
+	}
+
+	columns := []widget.Column{{Title: "#", Width: 3, Right: true}, {Title: "Date"}, {Title: "Type", Width: 10}, {Title: "Amount", Width: 12, Right: true}}
+	view.preview = widget.TableNew(columns, nil, false)
+	view.updatePreview()
+
+	return view
+}
+
+/*
+Build bond from values of form
+Return bonds.FieldError for value which can't be parsed, *bonds.ValidationError for invalid bond
+*/
+func (self *BondFormView) bond() (*bonds.BondsData, error) {
+	result := bonds.BondsDataNew()
+	var nextPayDate time.Time
+
+	for _, field := range self.form.Fields {
+		var err error
+
+		if field.Label == NextPayDateField {
+			if field.Value != "" {
+				nextPayDate, err = time.Parse(DefaultDateLayout, field.Value)
+			}
+		} else {
+			err = result.SetField(field.Label, field.Value)
+		}
+
+		if err != nil {
+			return nil, bonds.FieldError{Field: field.Label, Message: fmt.Sprintf("wrong value '%s'", field.Value)}
+		}
+	}
+
+	if !nextPayDate.IsZero() {
+		result.CouponPeriod = bonds.CouponPeriodCreate(result.CouponNearPayDate, nextPayDate)
+	}
+
+	return result, result.Validate()
+}
+
+/* Field of form which shows error of bond field, period is entered as next pay date */
+func formFieldOf(field string) string {
+	if field == "couponPeriod" {
+		return NextPayDateField
+	}
+
+	return field
+}
+
+/* Check value can be parsed, then check whole bond for errors of this field */
+func (self *BondFormView) validator(name, hint string) func(value string) error {
+	return func(value string) error {
+		var err error
+
+		if name == NextPayDateField {
+			if value != "" {
+				_, err = time.Parse(DefaultDateLayout, value)
+			}
+		} else {
+			err = bonds.BondsDataNew().SetField(name, value)
+		}
+
+		if err != nil {
+			return fmt.Errorf("wrong value '%s': %s", value, hint)
+		}
+
+		_, err = self.bond()
+		var bondErr *bonds.ValidationError
+
+		if errors.As(err, &bondErr) {
+			for _, item := range bondErr.Fields {
+				if formFieldOf(item.Field) == name {
+					return errors.New(item.Message)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+/* Calculate schedule of bond from form, errors of schedule fields shown instead of it */
+func (self *BondFormView) updatePreview() {
+	obj, err := self.bond()
+	var fieldErr bonds.FieldError
+	var bondErr *bonds.ValidationError
+
+	if errors.As(err, &fieldErr) {
+		self.preview.SetRows(nil)
+		self.preview.Footer = fmt.Sprintf("%s: %s", formFieldOf(fieldErr.Field), fieldErr.Message)
+		return
+	}
+
+	if errors.As(err, &bondErr) {
+		for _, item := range bondErr.Fields {
+			if slices.Contains(scheduleFields, item.Field) {
+				self.preview.SetRows(nil)
+				self.preview.Footer = fmt.Sprintf("%s: %s", formFieldOf(item.Field), item.Message)
+				return
+			}
+		}
+	}
+
+	obj.CalcAll()
+	flows := obj.CashFlows()
+	rows := make([][]string, 0, len(flows))
+	var total float64
+
+	for ind, flow := range flows {
+		rows = append(rows, []string{fmt.Sprint(ind + 1), flow.Date.Format(DefaultDateLayout), flow.Type, fmt.Sprintf("%.2f", flow.Amount)})
+		total += flow.Amount
+	}
+
+	self.preview.SetRows(rows)
+	self.preview.Footer = fmt.Sprintf("Total: %.2f in %d payments, maturity %s", total, len(flows), obj.MaturityDate().Format(DefaultDateLayout))
+}
+
+/* Append bond from form, called by form when all fields are valid */
+func (self *BondFormView) submit() error {
+	obj, err := self.bond()
+
+	if err != nil {
+		return err
+	}
+
+	AllBonds.Append(obj)
+	AutosaveState.Touch()
+	Terminal.Print(fmt.Sprintf("[%s] %s - created", obj.ID, obj.Name))
+	return nil
+}
+
+/* Form at left and preview at right, or preview under form if screen is narrow */
+func (self *BondFormView) draw() {
+	win := self.window.Window
+	inner := widget.Rect{SizeY: self.window.SizeY - 3, SizeX: self.window.SizeX - 2, PosY: 1, PosX: 1}
+	formY, formX := self.form.Size(inner.SizeY, inner.SizeX)
+
+	if inner.SizeX-formX-2 >= bondFormPreviewWidth {
+		self.formArea = widget.Rect{SizeY: inner.SizeY, SizeX: formX, PosY: inner.PosY, PosX: inner.PosX}
+		self.previewArea = widget.Rect{SizeY: inner.SizeY - 1, SizeX: inner.SizeX - formX - 2, PosY: inner.PosY + 1, PosX: inner.PosX + formX + 2}
+		win.VLine(inner.PosY, inner.PosX+formX, goncurses.ACS_VLINE, inner.SizeY)
+	} else {
+		formY = min(formY, max(inner.SizeY/2, 4))
+		self.formArea = widget.Rect{SizeY: formY, SizeX: inner.SizeX, PosY: inner.PosY, PosX: inner.PosX}
+		self.previewArea = widget.Rect{SizeY: inner.SizeY - formY - 1, SizeX: inner.SizeX, PosY: inner.PosY + formY + 1, PosX: inner.PosX}
+	}
+
+	self.form.Draw(win, self.formArea)
+	widget.PrintLine(win, self.previewArea.PosY-1, self.previewArea.PosX, self.previewArea.SizeX, bondFormPreviewHeader, widget.ActiveStyle.Title)
+
+	if self.previewArea.SizeY > 1 {
+		self.preview.Draw(win, self.previewArea)
+	}
+
+	help := fmt.Sprintf("%s/%s/%s - move, %s - next or save, esc - cancel",
+		ActiveKeymap.Name(ActionFocus), ActiveKeymap.Name(ActionUp), ActiveKeymap.Name(ActionDown), ActiveKeymap.Name(ActionSelect))
+	win.MovePrint(inner.PosY+inner.SizeY, inner.PosX, widget.Clip(help, inner.SizeX))
+}
+
+/* Create window over whole screen and push it to Navigator, window closed by saving or cancel */
+func (self *BondFormView) Open() error {
+	screen := ScreenRect()
+	window, err := FSMWindowNew(screen.SizeY, screen.SizeX, screen.PosY, screen.PosX)
+
+	if err != nil {
+		return err
+	}
+
+	self.window = window
+	closeIf := func(result widget.Result) bool {
+		if result == widget.ResultClose {
+			Navigator.Close(window)
+		}
+
+		return true
+	}
+
+	window.SetTitle("|New bond|").SetCustomDraw(self.draw).
+		RegisterDefaultInput(func(key goncurses.Key) bool {
+			return closeIf(self.form.Input(WidgetCommand(key, self.form), key))
+		}).
+		RegisterMouse(func(y, x int, button goncurses.MouseButton) bool {
+			wheel, clicks := WidgetMouse(button)
+			area := self.previewArea
+
+			if y >= area.PosY && y < area.PosY+area.SizeY && x >= area.PosX && x < area.PosX+area.SizeX {
+				return closeIf(self.preview.Mouse(y-area.PosY, x-area.PosX, wheel, clicks))
+			}
+
+			area = self.formArea
+			return closeIf(self.form.Mouse(y-area.PosY, x-area.PosX, wheel, clicks))
+		})
+
+	Navigator.Push(window)
+	return nil
+}
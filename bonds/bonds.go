@@ -2,106 +2,229 @@ package bonds
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"slices"
 	"time"
 )
 
 /* Struct for describe one bonds */
 type BondsData struct {
-	Name              string      `json:"name"`         // Bond name
-	CouponCount       int         `json:"couponCount"`  // Count of remaining coupon payments
-	CouponPeriod      int         `json:"couponPeriod"` // Period between coupon payments (Calc as NextDate - NearDate)
-	CouponNearPayDate time.Time   `json:"nearPayDate"`  // Near date of payment
-	PayDates          []time.Time `json:"-"`            // Calculated dates of coupon payments
+	ID                string      `json:"id"`                    // Stable unique identifier, generated while appending
+	Name              string      `json:"name"`                  // Bond name
+	ISIN              string      `json:"isin,omitempty"`        // International securities identification number, can be empty
+	CouponCount       int         `json:"couponCount"`           // Count of remaining coupon payments
+	CouponPeriod      int         `json:"couponPeriod"`          // Period between coupon payments (Calc as NextDate - NearDate)
+	CouponNearPayDate time.Time   `json:"nearPayDate"`           // Near date of payment
+	CouponValue       float64     `json:"couponValue,omitempty"` // Amount of one coupon payment for one bond
+	FaceValue         float64     `json:"faceValue,omitempty"`   // Amount returned at maturity for one bond
+	Price             float64     `json:"price,omitempty"`       // Purchase price of one bond, used for yield
+	Quantity          int         `json:"quantity,omitempty"`    // Count of owned bonds, zero means one
+	Currency          string      `json:"currency,omitempty"`    // Currency of payments, like 'RUB'
+	Category          string      `json:"category,omitempty"`    // One category, like risk bucket or account
+	Tags              []string    `json:"tags,omitempty"`        // Free-form tags, like issuer sector
+	Notes             string      `json:"notes,omitempty"`       // Free-form notes
+	PayDates          []time.Time `json:"-"`                     // Calculated dates of coupon payments
 }
 
 /* Struct for store multiply bonds */
 type Bonds struct {
-	Bonds []*BondsData
+	Bonds   []*BondsData
+	Broken  []BrokenEntry // Invalid entries from loaded file, not used in calculations and saved back unchanged
+	History *History      // Changes of bonds for undo and redo
+	dirty   bool          // Bonds changed after last saving or loading
 }
 
 var (
 	DefaultLocation, _ = time.LoadLocation("Asia/Yekaterinburg")
 )
 
+const (
+	DateLayout          = "02.01.2006" // Layout for show and parse pay dates
+	TemporaryFileSuffix = ".tmp"       // Suffix of file written by SaveToFile before replacing target
+)
+
 func BondsNew() *Bonds {
 	obj := new(Bonds)
 	obj.Bonds = make([]*BondsData, 0)
+	obj.History = HistoryNew(DefaultHistoryLimit)
 
 	return obj
 }
 
 /*
 Save all appended bonds into file as json
-Overwrite file if it exist
+Broken entries from loaded file are saved after bonds unchanged, so they are not lost
+Overwrite file if it exist, file is written into temporary file near it and then replaced,
+so interrupted saving doesn't leave truncated file
 */
 func (self *Bonds) SaveToFile(filename string) error {
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	temporary := filename + TemporaryFileSuffix
+	file, err := os.OpenFile(temporary, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
 
 	if err != nil {
 		return err
 	}
 
-	defer file.Close()
+	entries := make([]any, 0, len(self.Bonds)+len(self.Broken))
+
+	for _, obj := range self.Bonds {
+		entries = append(entries, obj)
+	}
+
+	for _, entry := range self.Broken {
+		entries = append(entries, entry.Raw)
+	}
 
 	encoder := json.NewEncoder(file)
-	err = encoder.Encode(self.Bonds)
+	err = encoder.Encode(entries)
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(temporary, filename)
+	}
 
 	if err != nil {
+		os.Remove(temporary)
 		return err
 	}
 
+	self.dirty = false
 	return nil
 }
 
 /*
-Save all appended bonds into file as json
-Overwrite current Bonds array
+Load bonds from json file
+Overwrite current Bonds array, keep it untouched if loading failed
+Invalid bonds and entries which are not bonds are skipped, kept in Broken and returned
 */
-func (self *Bonds) LoadFromFile(filename string) error {
+func (self *Bonds) LoadFromFile(filename string) ([]BrokenEntry, error) {
 	file, err := os.Open(filename)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer file.Close()
-	self.Bonds = make([]*BondsData, 0)
+	decoded := make([]json.RawMessage, 0)
 
 	encoder := json.NewDecoder(file)
-	err = encoder.Decode(&self.Bonds)
+	err = encoder.Decode(&decoded)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, obj := range self.Bonds {
+	loaded := make([]*BondsData, 0, len(decoded))
+	broken := make([]BrokenEntry, 0)
+
+	for ind, raw := range decoded {
+		obj := BondsDataNew()
+		err = json.Unmarshal(raw, obj)
+
+		if err == nil {
+			err = obj.Validate()
+		}
+
+		if err != nil {
+			broken = append(broken, BrokenEntry{ind, obj.Name, err, raw})
+			continue
+		}
+
 		obj.CalcAll()
+		loaded = append(loaded, obj)
 	}
 
+	before := self.snapshot()
+	self.Bonds = loaded
+	self.Broken = broken
+	idChanged := self.assignIDs()
+	self.record("load "+filename, before)
+	self.dirty = idChanged
+
+	return broken, nil
+}
+
+/* Check is bonds changed after last saving or loading */
+func (self *Bonds) IsDirty() bool {
+	return self.dirty
+}
+
+/* Replace bond by it's index with edited one, also call CalcAll for new bond */
+func (self *Bonds) Replace(index int, obj *BondsData) error {
+	if index >= len(self.Bonds) || index < 0 {
+		return fmt.Errorf("Index: %d out of bonds list with len: %d", index, len(self.Bonds))
+	}
+
+	before := self.snapshot()
+	obj.CalcAll()
+	self.Bonds[index] = obj
+	self.dirty = true
+	self.record("edit "+obj.Name, before)
+
 	return nil
 }
 
 /* Append new bonds, also call CalcAll for bond before append */
 func (self *Bonds) Append(obj *BondsData) {
+	before := self.snapshot()
+	self.add(obj)
+	self.record("new "+obj.Name, before)
+}
+
+/* Append new bonds without saving into history, generate ID if bond have no one or it already used */
+func (self *Bonds) add(obj *BondsData) {
+	if obj.ID == "" || self.IndexByID(obj.ID) >= 0 {
+		obj.ID = self.newID()
+	}
+
 	obj.CalcAll()
 	self.Bonds = append(self.Bonds, obj)
+	self.dirty = true
 }
 
-/* Count a payments by given year and month */
-func (self *Bonds) PayCountByYearMonth(year, month int) int {
-	var result int
-	validMonth := time.Month(month)
+/* Remove bond by it's index and return removed bond */
+func (self *Bonds) Remove(index int) (*BondsData, error) {
+	if index >= len(self.Bonds) || index < 0 {
+		return nil, fmt.Errorf("Index: %d out of bonds list with len: %d", index, len(self.Bonds))
+	}
 
-	for _, obj := range self.Bonds {
-		for _, date := range obj.PayDates {
-			if date.Year() == year && date.Month() == validMonth {
-				result++
-			}
-		}
+	before := self.snapshot()
+	obj := self.Bonds[index]
+	self.Bonds = append(self.Bonds[:index], self.Bonds[index+1:]...)
+	self.dirty = true
+	self.record("delete "+obj.Name, before)
+
+	return obj, nil
+}
+
+/* Append copy of bond by it's index with new ID and return the copy */
+func (self *Bonds) Copy(index int) (*BondsData, error) {
+	if index >= len(self.Bonds) || index < 0 {
+		return nil, fmt.Errorf("Index: %d out of bonds list with len: %d", index, len(self.Bonds))
 	}
 
-	return result
+	before := self.snapshot()
+	copied := *self.Bonds[index]
+	copied.ID = ""
+	copied.Name += " copy"
+	copied.Tags = slices.Clone(copied.Tags)
+	self.add(&copied)
+	self.record("copy "+self.Bonds[index].Name, before)
+
+	return &copied, nil
+}
+
+/* Count a payments by given year and month, coupons and redemptions are counted same as in PaymentsByYearMonth */
+func (self *Bonds) PayCountByYearMonth(year, month int) int {
+	return len(self.PaymentsByYearMonth(year, month))
 }
 
 /*
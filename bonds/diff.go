@@ -0,0 +1,135 @@
+/*
+Compare and merge two bonds containers
+Bonds matched by identity:
+    - ISIN, if both bonds have it
+    - Name and schedule otherwise: coupon period, near pay date and coupon count
+*/
+package bonds
+
+/* One field that differ between two same bonds */
+type FieldChange struct {
+	Field    string // Field name
+	OldValue string // Value in local bond
+	NewValue string // Value in other bond
+}
+
+/* Pair of same bonds with different fields */
+type BondsConflict struct {
+	Local   *BondsData
+	Other   *BondsData
+	Changes []FieldChange
+}
+
+/* Result of comparing two bonds containers */
+type BondsDiff struct {
+	Added   []*BondsData    // Exist only in other container
+	Removed []*BondsData    // Exist only in local container
+	Changed []BondsConflict // Exist in both, but have different fields
+}
+
+/* Check is diff have no any changes */
+func (self *BondsDiff) IsEmpty() bool {
+	return len(self.Added) == 0 && len(self.Removed) == 0 && len(self.Changed) == 0
+}
+
+/* Check is two bonds describe same security */
+func (self *BondsData) SameAs(other *BondsData) bool {
+	if self.ISIN != "" && other.ISIN != "" {
+		return self.ISIN == other.ISIN
+	}
+
+	return self.Name == other.Name && self.CouponPeriod == other.CouponPeriod &&
+		self.CouponNearPayDate.Equal(other.CouponNearPayDate) && self.CouponCount == other.CouponCount
+}
+
+/* Return all fields which differ from other bond */
+func (self *BondsData) Compare(other *BondsData) []FieldChange {
+	result := make([]FieldChange, 0)
+
+	for _, field := range EditableFields {
+		oldValue, _ := self.FieldValue(field)
+		newValue, _ := other.FieldValue(field)
+
+		if oldValue != newValue {
+			result = append(result, FieldChange{field, oldValue, newValue})
+		}
+	}
+
+	return result
+}
+
+/* Find bond same as given, return nil if not found */
+func (self *Bonds) FindSame(obj *BondsData) *BondsData {
+	for _, local := range self.Bonds {
+		if local.SameAs(obj) {
+			return local
+		}
+	}
+
+	return nil
+}
+
+/* Compare bonds with other container */
+func (self *Bonds) Diff(other *Bonds) BondsDiff {
+	var result BondsDiff
+
+	for _, otherObj := range other.Bonds {
+		local := self.FindSame(otherObj)
+
+		if local == nil {
+			result.Added = append(result.Added, otherObj)
+			continue
+		}
+
+		changes := local.Compare(otherObj)
+
+		if len(changes) != 0 {
+			result.Changed = append(result.Changed, BondsConflict{local, otherObj, changes})
+		}
+	}
+
+	for _, local := range self.Bonds {
+		if other.FindSame(local) == nil {
+			result.Removed = append(result.Removed, local)
+		}
+	}
+
+	return result
+}
+
+/*
+Apply diff from other container:
+  - Added bonds will be appended
+  - Removed bonds will be kept
+  - Changed fields will be set from other only if takeOther return true for them, other fields keep local values
+*/
+func (self *Bonds) Merge(diff BondsDiff, takeOther func(conflict BondsConflict, change FieldChange) bool) {
+	before := self.snapshot()
+
+	for _, conflict := range diff.Changed {
+		var changed bool
+
+		for _, change := range conflict.Changes {
+			if !takeOther(conflict, change) {
+				continue
+			}
+
+			// Value is formatted by FieldValue of other bond, so it is parsed back without errors
+			if conflict.Local.SetField(change.Field, change.NewValue) == nil {
+				changed = true
+			}
+		}
+
+		if changed {
+			conflict.Local.CalcAll()
+			self.dirty = true
+		}
+	}
+
+	for _, obj := range diff.Added {
+		copied := *obj
+		self.add(&copied)
+	}
+
+	self.record("merge", before)
+}
@@ -0,0 +1,119 @@
+package bonds
+
+import (
+	"testing"
+)
+
+func TestBondsSameAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		localISIN string
+		change    func(obj *BondsData)
+		want      bool
+	}{
+		{"same name and schedule", "", func(obj *BondsData) {}, true},
+		{"other coupon value", "", func(obj *BondsData) { obj.CouponValue = 12 }, true},
+		{"other name", "", func(obj *BondsData) { obj.Name = "ОФЗ 26240" }, false},
+		{"other period", "", func(obj *BondsData) { obj.CouponPeriod = 182 }, false},
+		{"other near pay date", "", func(obj *BondsData) { obj.CouponNearPayDate = CouponPayDay(2099, 3, 2) }, false},
+		{"other coupon count", "", func(obj *BondsData) { obj.CouponCount = 5 }, false},
+		{"isin only at one side", "", func(obj *BondsData) { obj.ISIN = "RU000A1038V6" }, true},
+		{"same isin other name", "RU000A1038V6", func(obj *BondsData) { obj.ISIN = "RU000A1038V6"; obj.Name = "Renamed" }, true},
+		{"other isin same schedule", "RU000A1038V6", func(obj *BondsData) { obj.ISIN = "RU000A105TU7" }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			local := testBond("aaaa1111", test.localISIN, "ОФЗ 26238")
+			other := testBond("bbbb2222", "", "ОФЗ 26238")
+			test.change(other)
+
+			if got := local.SameAs(other); got != test.want {
+				t.Errorf("SameAs = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBondsDiff(t *testing.T) {
+	local := BondsNew()
+	local.Append(testBond("aaaa1111", "", "By schedule"))
+	local.Append(testBond("bbbb2222", "RU000A1038V6", "By isin"))
+	local.Append(testBond("cccc3333", "", "Removed"))
+
+	other := BondsNew()
+	bySchedule := testBond("dddd4444", "", "By schedule")
+	bySchedule.Notes = "changed"
+	other.Append(bySchedule)
+	other.Append(testBond("eeee5555", "RU000A1038V6", "Renamed by isin"))
+	moved := testBond("ffff6666", "", "Removed")
+	moved.CouponNearPayDate = CouponPayDay(2099, 4, 1)
+	other.Append(moved)
+
+	diff := local.Diff(other)
+
+	if len(diff.Changed) != 2 || diff.Changed[0].Local.Name != "By schedule" || diff.Changed[1].Local.Name != "By isin" {
+		t.Errorf("changed = %+v, want bonds matched by schedule and by isin", diff.Changed)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0] != moved {
+		t.Errorf("added = %+v, want bond with other near pay date", diff.Added)
+	}
+
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "Removed" {
+		t.Errorf("removed = %+v, want bond with other near pay date", diff.Removed)
+	}
+}
+
+func TestBondsMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		take      map[string]bool // Fields taken from other bond
+		wantValue float64
+		wantNotes string
+	}{
+		{"keep local", map[string]bool{}, 10, "local"},
+		{"take other", map[string]bool{"couponValue": true, "notes": true}, 12, "other"},
+		{"take one field", map[string]bool{"couponValue": true}, 12, "local"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			local := BondsNew()
+			same := testBond("aaaa1111", "RU000A1038V6", "ОФЗ 26238")
+			same.Notes = "local"
+			local.Append(same)
+			local.Append(testBond("bbbb2222", "", "Removed"))
+
+			other := BondsNew()
+			changed := testBond("cccc3333", "RU000A1038V6", "ОФЗ 26238")
+			changed.CouponValue = 12
+			changed.Notes = "other"
+			other.Append(changed)
+			added := testBond("dddd4444", "", "Added")
+			other.Append(added)
+
+			diff := local.Diff(other)
+
+			if len(diff.Changed) != 1 || len(diff.Changed[0].Changes) != 2 {
+				t.Fatalf("Diff changed = %+v, want one bond with couponValue and notes", diff.Changed)
+			}
+
+			local.Merge(diff, func(conflict BondsConflict, change FieldChange) bool {
+				return test.take[change.Field]
+			})
+
+			if same.CouponValue != test.wantValue || same.Notes != test.wantNotes {
+				t.Errorf("merged bond has couponValue %v and notes %q, want %v and %q", same.CouponValue, same.Notes, test.wantValue, test.wantNotes)
+			}
+
+			if len(local.Bonds) != 3 || local.Bonds[1].Name != "Removed" || local.Bonds[2].Name != "Added" {
+				t.Fatalf("merged bonds = %d, want removed bond kept and added bond appended", len(local.Bonds))
+			}
+
+			if local.Bonds[2] == added {
+				t.Errorf("added bond is shared with other container, want copy")
+			}
+		})
+	}
+}
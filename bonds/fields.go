@@ -0,0 +1,181 @@
+/*
+Access to bond fields by their json names
+Used for show, compare and edit bonds as strings
+*/
+package bonds
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TagsSeparator = "," // Separator of tags in string value of field
+)
+
+var (
+	EditableFields = []string{
+		"name", "isin", "couponCount", "couponPeriod", "nearPayDate",
+		"couponValue", "faceValue", "price", "quantity",
+		"currency", "category", "tags", "notes",
+	}
+)
+
+/* Return field value as string, return error for unknown field */
+func (self *BondsData) FieldValue(field string) (string, error) {
+	switch field {
+	case "name":
+		return self.Name, nil
+
+	case "isin":
+		return self.ISIN, nil
+
+	case "couponCount":
+		return strconv.Itoa(self.CouponCount), nil
+
+	case "couponPeriod":
+		return strconv.Itoa(self.CouponPeriod), nil
+
+	case "nearPayDate":
+		return self.CouponNearPayDate.Format(DateLayout), nil
+
+	case "couponValue":
+		return formatAmount(self.CouponValue), nil
+
+	case "faceValue":
+		return formatAmount(self.FaceValue), nil
+
+	case "price":
+		return formatAmount(self.Price), nil
+
+	case "quantity":
+		return strconv.Itoa(self.Quantity), nil
+
+	case "currency":
+		return self.Currency, nil
+
+	case "category":
+		return self.Category, nil
+
+	case "tags":
+		return strings.Join(self.Tags, TagsSeparator), nil
+
+	case "notes":
+		return self.Notes, nil
+	}
+
+	return "", fmt.Errorf("Unknown field: '%s'", field)
+}
+
+/* Parse value and set it into field, CalcAll must be called after */
+func (self *BondsData) SetField(field, value string) error {
+	var err error
+
+	switch field {
+	case "name":
+		self.Name = value
+
+	case "isin":
+		self.ISIN = strings.ToUpper(strings.TrimSpace(value))
+
+	case "couponCount":
+		var count int
+		count, err = strconv.Atoi(value)
+
+		if err == nil {
+			self.CouponCount = count
+		}
+
+	case "couponPeriod":
+		var period int
+		period, err = strconv.Atoi(value)
+
+		if err == nil {
+			self.CouponPeriod = period
+		}
+
+	case "nearPayDate":
+		var date time.Time
+		date, err = time.Parse(DateLayout, value)
+
+		if err == nil {
+			self.CouponNearPayDate = date
+		}
+
+	case "couponValue":
+		err = parseAmount(value, &self.CouponValue)
+
+	case "faceValue":
+		err = parseAmount(value, &self.FaceValue)
+
+	case "price":
+		err = parseAmount(value, &self.Price)
+
+	case "quantity":
+		var quantity int
+		quantity, err = strconv.Atoi(value)
+
+		if err == nil {
+			self.Quantity = quantity
+		}
+
+	case "currency":
+		self.Currency = strings.ToUpper(strings.TrimSpace(value))
+
+	case "category":
+		self.Category = strings.TrimSpace(value)
+
+	case "tags":
+		self.Tags = ParseTags(value)
+
+	case "notes":
+		self.Notes = value
+
+	default:
+		err = fmt.Errorf("Unknown field: '%s'", field)
+	}
+
+	return err
+}
+
+/* Split tags by separator, trim spaces and skip empty and repeated tags */
+func ParseTags(value string) []string {
+	result := make([]string, 0)
+
+	for _, tag := range strings.Split(value, TagsSeparator) {
+		tag = strings.TrimSpace(tag)
+
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+/* Format amount without trailing zeros */
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+/* Parse amount with '.' or ',' as decimal separator, empty value is zero */
+func parseAmount(value string, result *float64) error {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+
+	if value == "" {
+		*result = 0
+		return nil
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return err
+	}
+
+	*result = amount
+	return nil
+}
@@ -0,0 +1,38 @@
+package bonds
+
+import (
+	"testing"
+)
+
+func TestSetFieldISIN(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		valid bool
+	}{
+		{"upper case", "RU000A1038V6", "RU000A1038V6", true},
+		{"lower case", "ru000a1038v6", "RU000A1038V6", true},
+		{"spaces around", " ru000a1038v6 ", "RU000A1038V6", true},
+		{"empty", "", "", true},
+		{"wrong", "ru000", "RU000", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			obj := testBond("aaaa1111", "", "ОФЗ 26238")
+
+			if err := obj.SetField("isin", test.value); err != nil {
+				t.Fatal(err)
+			}
+
+			if obj.ISIN != test.want {
+				t.Errorf("ISIN = %q, want %q", obj.ISIN, test.want)
+			}
+
+			if err := obj.Validate(); (err == nil) != test.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, test.valid)
+			}
+		})
+	}
+}
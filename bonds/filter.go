@@ -0,0 +1,116 @@
+/*
+Filter expression for select bonds
+Expression is space separated terms, bond must match all of them:
+  - 'tag:<tag>' - bond have tag
+  - '<field>:<value>' - field from EditableFields equal value, case insensitive
+  - '<word>' - name starts with word, case insensitive
+  - '!' before term negate it, like '!tag:bank'
+*/
+package bonds
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/* One condition of filter */
+type filterTerm struct {
+	key    string // Field name, empty for name prefix
+	value  string // Lowercased value
+	negate bool
+}
+
+/* Parsed filter expression */
+type Filter struct {
+	Expression string
+	terms      []filterTerm
+}
+
+/* Parse filter expression, return nil filter without error for empty expression */
+func ParseFilter(expression string) (*Filter, error) {
+	expression = strings.TrimSpace(expression)
+
+	if expression == "" {
+		return nil, nil
+	}
+
+	obj := new(Filter)
+	obj.Expression = expression
+	obj.terms = make([]filterTerm, 0)
+
+	for _, word := range strings.Fields(expression) {
+		var term filterTerm
+
+		if strings.HasPrefix(word, "!") {
+			term.negate = true
+			word = word[1:]
+		}
+
+		key, value, hasKey := strings.Cut(word, ":")
+
+		if !hasKey {
+			value = key
+			key = ""
+		} else if key != "tag" && !slices.Contains(EditableFields, key) {
+			return nil, fmt.Errorf("Unknown filter field: '%s'", key)
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("Empty value in filter term: '%s'", word)
+		}
+
+		term.key = key
+		term.value = strings.ToLower(value)
+		obj.terms = append(obj.terms, term)
+	}
+
+	return obj, nil
+}
+
+/* Check is bond match all filter terms, nil filter match any bond */
+func (self *Filter) Match(obj *BondsData) bool {
+	if self == nil {
+		return true
+	}
+
+	for _, term := range self.terms {
+		if term.match(obj) == term.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (self filterTerm) match(obj *BondsData) bool {
+	switch self.key {
+	case "":
+		return strings.HasPrefix(strings.ToLower(obj.Name), self.value)
+
+	case "tag":
+		for _, tag := range obj.Tags {
+			if strings.ToLower(tag) == self.value {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	value, err := obj.FieldValue(self.key)
+	return err == nil && strings.ToLower(value) == self.value
+}
+
+/* Return new container with bonds matched by filter, bonds are shared, not copied */
+func (self *Bonds) Filter(filter *Filter) *Bonds {
+	result := BondsNew()
+
+	for _, obj := range self.Bonds {
+		if filter.Match(obj) {
+			result.Bonds = append(result.Bonds, obj)
+		}
+	}
+
+	return result
+}
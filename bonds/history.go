@@ -0,0 +1,131 @@
+/*
+History of bonds changes for undo and redo
+Each change stored as copies of all bonds and kept invalid entries before and after it
+*/
+package bonds
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	DefaultHistoryLimit = 50 // Maximum count of stored changes
+)
+
+/* State of bonds container stored in history */
+type bondsState struct {
+	bonds  []BondsData
+	broken []BrokenEntry // Invalid entries of loaded file, they are saved back with bonds
+}
+
+/* One reversible change of bonds */
+type Operation struct {
+	Description string
+	before      bondsState
+	after       bondsState
+}
+
+/* Stacks of done and undone changes */
+type History struct {
+	Limit  int
+	done   []Operation
+	undone []Operation
+}
+
+func HistoryNew(limit int) *History {
+	obj := new(History)
+	obj.Limit = limit
+	obj.done = make([]Operation, 0)
+	obj.undone = make([]Operation, 0)
+
+	return obj
+}
+
+/* Push done change, forget all undone changes and oldest changes above limit */
+func (self *History) push(op Operation) {
+	self.done = append(self.done, op)
+	self.undone = self.undone[:0]
+
+	if len(self.done) > self.Limit {
+		self.done = self.done[len(self.done)-self.Limit:]
+	}
+}
+
+/* Forget all changes */
+func (self *History) Clear() {
+	self.done = self.done[:0]
+	self.undone = self.undone[:0]
+}
+
+/* Return descriptions of done changes from oldest to newest, and undone changes from next to redo */
+func (self *History) Descriptions() (done []string, undone []string) {
+	for _, op := range self.done {
+		done = append(done, op.Description)
+	}
+
+	for ind := len(self.undone) - 1; ind >= 0; ind-- {
+		undone = append(undone, self.undone[ind].Description)
+	}
+
+	return done, undone
+}
+
+/* Copy all bonds and invalid entries for store in history */
+func (self *Bonds) snapshot() bondsState {
+	result := bondsState{make([]BondsData, 0, len(self.Bonds)), slices.Clone(self.Broken)}
+
+	for _, obj := range self.Bonds {
+		result.bonds = append(result.bonds, *obj)
+	}
+
+	return result
+}
+
+/* Replace all bonds and invalid entries with copies from history */
+func (self *Bonds) restore(state bondsState) {
+	self.Bonds = make([]*BondsData, 0, len(state.bonds))
+
+	for _, obj := range state.bonds {
+		copied := obj
+		self.Bonds = append(self.Bonds, &copied)
+	}
+
+	self.Broken = slices.Clone(state.broken)
+	self.dirty = true
+}
+
+/* Save change into history, before must be taken by snapshot before change */
+func (self *Bonds) record(description string, before bondsState) {
+	self.History.push(Operation{description, before, self.snapshot()})
+}
+
+/* Revert last change and return it description */
+func (self *Bonds) Undo() (string, error) {
+	if len(self.History.done) == 0 {
+		return "", fmt.Errorf("Nothing to undo")
+	}
+
+	last := len(self.History.done) - 1
+	op := self.History.done[last]
+	self.History.done = self.History.done[:last]
+	self.History.undone = append(self.History.undone, op)
+	self.restore(op.before)
+
+	return op.Description, nil
+}
+
+/* Apply last undone change again and return it description */
+func (self *Bonds) Redo() (string, error) {
+	if len(self.History.undone) == 0 {
+		return "", fmt.Errorf("Nothing to redo")
+	}
+
+	last := len(self.History.undone) - 1
+	op := self.History.undone[last]
+	self.History.undone = self.History.undone[:last]
+	self.History.done = append(self.History.done, op)
+	self.restore(op.after)
+
+	return op.Description, nil
+}
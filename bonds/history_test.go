@@ -0,0 +1,57 @@
+package bonds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBondsUndoRedoBroken(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "loaded.json")
+	content := `[{"id": "bbbb2222", "name": "Loaded", "couponCount": 1, "nearPayDate": "2099-03-01T00:00:00Z"}, {"name": ""}]`
+
+	if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	list := BondsNew()
+	list.Append(testBond("aaaa1111", "", "Appended"))
+
+	if _, err := list.LoadFromFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		action     func() (string, error)
+		wantBonds  []string
+		wantBroken int
+	}{
+		{"undo load", list.Undo, []string{"Appended"}, 0},
+		{"redo load", list.Redo, []string{"Loaded"}, 1},
+		{"undo load again", list.Undo, []string{"Appended"}, 0},
+		{"undo append", list.Undo, []string{}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.action(); err != nil {
+				t.Fatal(err)
+			}
+
+			names := make([]string, 0, len(list.Bonds))
+
+			for _, obj := range list.Bonds {
+				names = append(names, obj.Name)
+			}
+
+			if len(names) != len(test.wantBonds) || len(names) != 0 && names[0] != test.wantBonds[0] {
+				t.Errorf("bonds = %q, want %q", names, test.wantBonds)
+			}
+
+			if len(list.Broken) != test.wantBroken {
+				t.Errorf("broken entries = %d, want %d", len(list.Broken), test.wantBroken)
+			}
+		})
+	}
+}
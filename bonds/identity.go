@@ -0,0 +1,111 @@
+/*
+Stable bond identifiers
+Each bond have random ID saved in json, it never change after creation
+Bonds can be found by ID, ISIN, name prefix or list index with '#' prefix
+*/
+package bonds
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const (
+	IDLength    = 8   // Length of generated ID in hex chars
+	IndexPrefix = "#" // Prefix for address bond by list index, like '#3'
+)
+
+/* Generate new random ID, which not used by any bond in container */
+func (self *Bonds) newID() string {
+	buffer := make([]byte, IDLength/2)
+
+	for {
+		rand.Read(buffer)
+		id := hex.EncodeToString(buffer)
+
+		if self.IndexByID(id) < 0 {
+			return id
+		}
+	}
+}
+
+/* Set new ID for bonds without ID or with ID used by previous bonds, return true if any ID changed */
+func (self *Bonds) assignIDs() bool {
+	var changed bool
+	used := make(map[string]bool)
+
+	for _, obj := range self.Bonds {
+		if obj.ID == "" || used[obj.ID] {
+			obj.ID = self.newID()
+			changed = true
+		}
+
+		used[obj.ID] = true
+	}
+
+	return changed
+}
+
+/* Return index of bond with given ID or -1 if not found */
+func (self *Bonds) IndexByID(id string) int {
+	for ind, obj := range self.Bonds {
+		if obj.ID == id {
+			return ind
+		}
+	}
+
+	return -1
+}
+
+/*
+Return indices of all bonds matched by query, first non-empty group wins:
+  - '#<index>' - list index
+  - Exact ID
+  - Exact ISIN (case insensitive)
+  - Name prefix (case insensitive)
+*/
+func (self *Bonds) Lookup(query string) []int {
+	result := make([]int, 0)
+
+	if query == "" {
+		return result
+	}
+
+	if strings.HasPrefix(query, IndexPrefix) {
+		index, err := strconv.Atoi(strings.TrimPrefix(query, IndexPrefix))
+
+		if err == nil && index >= 0 && index < len(self.Bonds) {
+			result = append(result, index)
+		}
+
+		return result
+	}
+
+	index := self.IndexByID(query)
+
+	if index >= 0 {
+		return append(result, index)
+	}
+
+	for ind, obj := range self.Bonds {
+		if obj.ISIN != "" && strings.EqualFold(obj.ISIN, query) {
+			result = append(result, ind)
+		}
+	}
+
+	if len(result) != 0 {
+		return result
+	}
+
+	lowerQuery := strings.ToLower(query)
+
+	for ind, obj := range self.Bonds {
+		if strings.HasPrefix(strings.ToLower(obj.Name), lowerQuery) {
+			result = append(result, ind)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,54 @@
+package bonds
+
+import (
+	"slices"
+	"testing"
+)
+
+/* Bond with schedule in far future, so CalcAll keeps all pay dates */
+func testBond(id, isin, name string) *BondsData {
+	obj := BondsDataNew()
+	obj.ID = id
+	obj.ISIN = isin
+	obj.Name = name
+	obj.CouponCount = 4
+	obj.CouponPeriod = 91
+	obj.CouponNearPayDate = CouponPayDay(2099, 3, 1)
+	obj.CouponValue = 10
+
+	return obj
+}
+
+func TestBondsLookup(t *testing.T) {
+	list := BondsNew()
+	list.Append(testBond("aaaa1111", "RU000A1038V6", "ОФЗ 26238"))
+	list.Append(testBond("bbbb2222", "", "ОФЗ 26240"))
+	list.Append(testBond("cccc3333", "RU000A105TU7", "aaaa1111 fund"))
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"empty", "", []int{}},
+		{"index", "#1", []int{1}},
+		{"index out of range", "#3", []int{}},
+		{"index not a number", "#x", []int{}},
+		{"id", "bbbb2222", []int{1}},
+		{"id before name", "aaaa1111", []int{0}},
+		{"isin case insensitive", "ru000a105tu7", []int{2}},
+		{"name prefix case insensitive", "офз", []int{0, 1}},
+		{"name prefix unique", "ОФЗ 26240", []int{1}},
+		{"not found", "none", []int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := list.Lookup(test.query)
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Lookup(%q) = %v, want %v", test.query, got, test.want)
+			}
+		})
+	}
+}
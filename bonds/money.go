@@ -0,0 +1,147 @@
+/*
+Calculated amounts and dates of bond
+All amounts are for whole owned quantity
+*/
+package bonds
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	daysInYear = 365.0
+
+	CouponFlow     = "coupon"     // Type of cash flow with coupon payment
+	RedemptionFlow = "redemption" // Type of cash flow with face value return
+)
+
+/* One scheduled payment of bond */
+type CashFlow struct {
+	Date   time.Time
+	Type   string  // CouponFlow or RedemptionFlow
+	Amount float64 // Amount for all owned bonds
+}
+
+/* Cash flow of one bond in container */
+type Payment struct {
+	Bond *BondsData
+	CashFlow
+}
+
+/* Count of owned bonds, zero quantity means one bond */
+func (self *BondsData) Owned() int {
+	return max(self.Quantity, 1)
+}
+
+/* Amount of one coupon payment for all owned bonds */
+func (self *BondsData) CouponAmount() float64 {
+	return self.CouponValue * float64(self.Owned())
+}
+
+/* Amount returned at maturity for all owned bonds */
+func (self *BondsData) RedemptionAmount() float64 {
+	return self.FaceValue * float64(self.Owned())
+}
+
+/* Return next pay date, or near pay date if no dates calculated */
+func (self *BondsData) NextPayDate() time.Time {
+	if len(self.PayDates) == 0 {
+		return self.CouponNearPayDate
+	}
+
+	return self.PayDates[0]
+}
+
+/* Return date of last coupon payment, when face value returned */
+func (self *BondsData) MaturityDate() time.Time {
+	if len(self.PayDates) == 0 {
+		return self.CouponNearPayDate
+	}
+
+	return self.PayDates[len(self.PayDates)-1]
+}
+
+/*
+Simple yield to maturity in percents per year:
+    (remaining coupons + face value - price) / price / years to maturity
+Return zero if price is unknown
+*/
+func (self *BondsData) Yield() float64 {
+	if self.Price <= 0 {
+		return 0
+	}
+
+	years := time.Until(self.MaturityDate()).Hours() / 24 / daysInYear
+
+	if years <= 0 {
+		return 0
+	}
+
+	income := self.CouponValue*float64(len(self.PayDates)) + self.FaceValue - self.Price
+	return income / self.Price / years * 100
+}
+
+/* Return all scheduled payments: coupon for each pay date and redemption at maturity if face value known */
+func (self *BondsData) CashFlows() []CashFlow {
+	result := make([]CashFlow, 0, len(self.PayDates)+1)
+
+	for _, date := range self.PayDates {
+		result = append(result, CashFlow{date, CouponFlow, self.CouponAmount()})
+	}
+
+	if self.FaceValue > 0 {
+		result = append(result, CashFlow{self.MaturityDate(), RedemptionFlow, self.RedemptionAmount()})
+	}
+
+	return result
+}
+
+/* Return count of whole days from now until cash flow, negative for past */
+func (self CashFlow) DaysUntil() int {
+	return int(time.Until(self.Date).Hours() / 24)
+}
+
+/* Return payments of all bonds accepted by match, sorted by date */
+func (self *Bonds) PaymentsWhere(match func(flow CashFlow) bool) []Payment {
+	result := make([]Payment, 0)
+
+	for _, obj := range self.Bonds {
+		for _, flow := range obj.CashFlows() {
+			if match(flow) {
+				result = append(result, Payment{obj, flow})
+			}
+		}
+	}
+
+	slices.SortStableFunc(result, func(first, second Payment) int {
+		return first.Date.Compare(second.Date)
+	})
+
+	return result
+}
+
+/* Return all payments with date in [from, to) */
+func (self *Bonds) PaymentsBetween(from, to time.Time) []Payment {
+	return self.PaymentsWhere(func(flow CashFlow) bool {
+		return !flow.Date.Before(from) && flow.Date.Before(to)
+	})
+}
+
+/* Return all payments with date in given month */
+func (self *Bonds) PaymentsByYearMonth(year, month int) []Payment {
+	return self.PaymentsWhere(func(flow CashFlow) bool {
+		return flow.Date.Year() == year && flow.Date.Month() == time.Month(month)
+	})
+}
+
+/* Sum amounts of payments */
+func PaymentsTotal(payments []Payment) float64 {
+	var result float64
+
+	for _, payment := range payments {
+		result += payment.Amount
+	}
+
+	return result
+}
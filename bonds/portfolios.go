@@ -0,0 +1,165 @@
+package bonds
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+/* Struct for store multiply named bonds containers, one of them is active */
+type Portfolios struct {
+	Names  []string          // Names in order of creation
+	Items  map[string]*Bonds // Bonds container by name
+	Active string            // Name of active portfolio
+}
+
+/* Create portfolios with one empty active portfolio with given name */
+func PortfoliosNew(name string) *Portfolios {
+	obj := new(Portfolios)
+	obj.Names = make([]string, 0)
+	obj.Items = make(map[string]*Bonds)
+	obj.Create(name)
+	obj.Active = name
+
+	return obj
+}
+
+/* Return active bonds container */
+func (self *Portfolios) Current() *Bonds {
+	return self.Items[self.Active]
+}
+
+/*
+Check portfolio name, it is used as file name in data directory:
+  - Name must not be empty
+  - Name must not contain path separators and must not be '.' or '..'
+*/
+func CheckPortfolioName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Portfolio name can't be empty")
+	}
+
+	if strings.ContainsAny(name, `/\`) || filepath.Base(name) != name || name == "." || name == ".." {
+		return fmt.Errorf("Portfolio name '%s' can't contain path separators or be '.' or '..'", name)
+	}
+
+	return nil
+}
+
+/* Create new empty portfolio, return error if name is invalid or already exist */
+func (self *Portfolios) Create(name string) error {
+	if err := CheckPortfolioName(name); err != nil {
+		return err
+	}
+
+	_, exist := self.Items[name]
+
+	if exist {
+		return fmt.Errorf("Portfolio '%s' already exist", name)
+	}
+
+	self.Items[name] = BondsNew()
+	self.Names = append(self.Names, name)
+
+	return nil
+}
+
+/* Set portfolio with given name as active */
+func (self *Portfolios) Switch(name string) error {
+	_, exist := self.Items[name]
+
+	if !exist {
+		return fmt.Errorf("Unknown portfolio: '%s'", name)
+	}
+
+	self.Active = name
+	return nil
+}
+
+/* Rename portfolio, keep it order and active state */
+func (self *Portfolios) Rename(oldName, newName string) error {
+	obj, exist := self.Items[oldName]
+
+	if !exist {
+		return fmt.Errorf("Unknown portfolio: '%s'", oldName)
+	}
+
+	if err := CheckPortfolioName(newName); err != nil {
+		return err
+	}
+
+	_, exist = self.Items[newName]
+
+	if exist {
+		return fmt.Errorf("Portfolio '%s' already exist", newName)
+	}
+
+	delete(self.Items, oldName)
+	self.Items[newName] = obj
+
+	for ind, name := range self.Names {
+		if name == oldName {
+			self.Names[ind] = newName
+		}
+	}
+
+	if self.Active == oldName {
+		self.Active = newName
+	}
+
+	return nil
+}
+
+/*
+Remove portfolio with given name
+Last portfolio can't be closed
+If active portfolio closed, first one became active
+*/
+func (self *Portfolios) Close(name string) error {
+	_, exist := self.Items[name]
+
+	if !exist {
+		return fmt.Errorf("Unknown portfolio: '%s'", name)
+	}
+
+	if len(self.Names) == 1 {
+		return fmt.Errorf("Can't close last portfolio")
+	}
+
+	delete(self.Items, name)
+
+	for ind, val := range self.Names {
+		if val == name {
+			self.Names = append(self.Names[:ind], self.Names[ind+1:]...)
+			break
+		}
+	}
+
+	if self.Active == name {
+		self.Active = self.Names[0]
+	}
+
+	return nil
+}
+
+/* Return new container with bonds from all portfolios, bonds are shared, not copied */
+func (self *Portfolios) Aggregated() *Bonds {
+	result := BondsNew()
+
+	for _, name := range self.Names {
+		result.Bonds = append(result.Bonds, self.Items[name].Bonds...)
+	}
+
+	return result
+}
+
+/* Check is any portfolio changed after last saving or loading */
+func (self *Portfolios) IsDirty() bool {
+	for _, obj := range self.Items {
+		if obj.IsDirty() {
+			return true
+		}
+	}
+
+	return false
+}
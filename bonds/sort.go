@@ -0,0 +1,62 @@
+package bonds
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	SortKeys = []string{"name", "next", "maturity", "coupon", "yield"} // Keys for SortIndices
+)
+
+/*
+Sort indices of bonds by key from SortKeys
+Bonds with equal keys keep their order
+*/
+func (self *Bonds) SortIndices(indices []int, key string, descending bool) error {
+	var compare func(first, second *BondsData) int
+
+	switch key {
+	case "name":
+		compare = func(first, second *BondsData) int {
+			return strings.Compare(strings.ToLower(first.Name), strings.ToLower(second.Name))
+		}
+
+	case "next":
+		compare = func(first, second *BondsData) int {
+			return first.NextPayDate().Compare(second.NextPayDate())
+		}
+
+	case "maturity":
+		compare = func(first, second *BondsData) int {
+			return first.MaturityDate().Compare(second.MaturityDate())
+		}
+
+	case "coupon":
+		compare = func(first, second *BondsData) int {
+			return cmp.Compare(first.CouponAmount(), second.CouponAmount())
+		}
+
+	case "yield":
+		compare = func(first, second *BondsData) int {
+			return cmp.Compare(first.Yield(), second.Yield())
+		}
+
+	default:
+		return fmt.Errorf("Unknown sort key: '%s'", key)
+	}
+
+	slices.SortStableFunc(indices, func(first, second int) int {
+		result := compare(self.Bonds[first], self.Bonds[second])
+
+		if descending {
+			return -result
+		}
+
+		return result
+	})
+
+	return nil
+}
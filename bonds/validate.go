@@ -0,0 +1,111 @@
+/*
+Validation of bond fields
+Fields in errors named same as in json and EditableFields
+*/
+package bonds
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	isinPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{9}[0-9]$`)
+)
+
+/* One invalid field with reason */
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (self FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", self.Field, self.Message)
+}
+
+/* All invalid fields of one bond */
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (self *ValidationError) Error() string {
+	messages := make([]string, 0, len(self.Fields))
+
+	for _, field := range self.Fields {
+		messages = append(messages, field.Error())
+	}
+
+	return "Invalid bond: " + strings.Join(messages, "; ")
+}
+
+/* Bond from file which was skipped while loading, kept to be saved back unchanged */
+type BrokenEntry struct {
+	Index int    // Index of entry in file
+	Name  string // Bond name from file, can be empty
+	Err   error
+	Raw   json.RawMessage // Entry as it is in file
+}
+
+func (self BrokenEntry) Error() string {
+	return fmt.Sprintf("Entry %d '%s': %s", self.Index, self.Name, self.Err.Error())
+}
+
+/*
+Check bond fields, return *ValidationError with all invalid fields or nil:
+  - Name must not be empty
+  - ISIN can be empty, otherwise 2 letters, 9 letters or digits and 1 digit
+  - At least one coupon remaining
+  - Period must be positive if there are more than one coupon
+  - Amounts and quantity must not be negative
+  - Near pay date must be setted
+*/
+func (self *BondsData) Validate() error {
+	var result ValidationError
+	addError := func(field, message string) {
+		result.Fields = append(result.Fields, FieldError{field, message})
+	}
+
+	if strings.TrimSpace(self.Name) == "" {
+		addError("name", "must not be empty")
+	}
+
+	if self.ISIN != "" && !isinPattern.MatchString(self.ISIN) {
+		addError("isin", fmt.Sprintf("'%s' is not valid ISIN (like RU000A0JX0J2)", self.ISIN))
+	}
+
+	if self.CouponCount < 1 {
+		addError("couponCount", fmt.Sprintf("must be at least 1, got %d", self.CouponCount))
+	}
+
+	if self.CouponCount > 1 && self.CouponPeriod <= 0 {
+		addError("couponPeriod", fmt.Sprintf("must be positive days between payments, got %d (is next date before near one?)", self.CouponPeriod))
+	}
+
+	if self.CouponValue < 0 {
+		addError("couponValue", "must not be negative")
+	}
+
+	if self.FaceValue < 0 {
+		addError("faceValue", "must not be negative")
+	}
+
+	if self.Price < 0 {
+		addError("price", "must not be negative")
+	}
+
+	if self.Quantity < 0 {
+		addError("quantity", "must not be negative")
+	}
+
+	if self.CouponNearPayDate.IsZero() {
+		addError("nearPayDate", "must be setted")
+	}
+
+	if len(result.Fields) != 0 {
+		return &result
+	}
+
+	return nil
+}
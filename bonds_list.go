@@ -0,0 +1,363 @@
+/*
+Scrollable pop up window with list of bonds, pushed to Navigator
+Features:
+    highlighted cursor row with actions for bond under it
+    sorting by keys from bonds.SortKeys
+    incremental search by name, id, isin, category and tags
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"bonds_payment_calendar/terminal"
+	"bonds_payment_calendar/widget"
+	"fmt"
+	"strings"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	FirstSortKey = '1' // Keys '1'..'5' sort by bonds.SortKeys, same key again change direction
+)
+
+type BondsListView struct {
+	Source   *bonds.Bonds
+	Filter   *bonds.Filter
+	SortKey  string // Key from bonds.SortKeys, empty for order of appending
+	SortDesc bool
+	Query    []rune // Search query, empty if search not active
+
+	window *FSMWindow
+	table  *widget.Table // Cursor of table is row under cursor
+	rows   []int         // Indices of shown bonds in Source, in display order
+}
+
+func BondsListViewNew(source *bonds.Bonds, filter *bonds.Filter) *BondsListView {
+	obj := new(BondsListView)
+	obj.Source = source
+	obj.Filter = filter
+	obj.table = widget.TableNew(bondsListColumns, nil, true)
+	obj.table.Marked = obj.isMatch
+	obj.table.OnSelect = func(ind int) widget.Result {
+		obj.runForSelected("show")
+		return widget.ResultNone
+	}
+	obj.update()
+
+	return obj
+}
+
+/* Columns of list, values are formatted by bondRow */
+var bondsListColumns = []widget.Column{
+	{Title: "#", Right: true},
+	{Title: "ID"},
+	{Title: "Name"},
+	{Title: "Coupons", Right: true},
+	{Title: "Near pay"},
+	{Title: "Period", Right: true},
+	{Title: "Coupon", Right: true},
+	{Title: "Yield", Right: true},
+	{Title: "Category"},
+	{Title: "Tags"},
+}
+
+/* Values of one bond for columns of list, index is index in full list */
+func bondRow(index int, obj *bonds.BondsData) []string {
+	var coupon, yield string
+
+	if obj.CouponValue != 0 {
+		coupon = fmt.Sprintf("%.2f", obj.CouponAmount())
+	}
+
+	if obj.Price != 0 {
+		yield = fmt.Sprintf("%.2f%%", obj.Yield())
+	}
+
+	return []string{
+		fmt.Sprint(index),
+		obj.ID,
+		obj.Name,
+		fmt.Sprint(obj.CouponCount),
+		obj.CouponNearPayDate.Format(DefaultDateLayout),
+		fmt.Sprint(obj.CouponPeriod),
+		coupon,
+		yield,
+		obj.Category,
+		strings.Join(obj.Tags, bonds.TagsSeparator),
+	}
+}
+
+/* Check is bond match search query */
+func (self *BondsListView) isMatch(row int) bool {
+	if len(self.Query) == 0 {
+		return false
+	}
+
+	obj := self.Source.Bonds[self.rows[row]]
+	text := strings.ToLower(strings.Join([]string{obj.Name, obj.ID, obj.ISIN, obj.Category, strings.Join(obj.Tags, " ")}, " "))
+	return strings.Contains(text, strings.ToLower(string(self.Query)))
+}
+
+/* Rebuild rows by filter and sort key */
+func (self *BondsListView) update() {
+	self.rows = make([]int, 0, len(self.Source.Bonds))
+	values := make([][]string, 0, len(self.Source.Bonds))
+
+	for ind, obj := range self.Source.Bonds {
+		if self.Filter.Match(obj) {
+			self.rows = append(self.rows, ind)
+		}
+	}
+
+	if self.SortKey != "" {
+		self.Source.SortIndices(self.rows, self.SortKey, self.SortDesc)
+	}
+
+	for _, index := range self.rows {
+		values = append(values, bondRow(index, self.Source.Bonds[index]))
+	}
+
+	self.table.SetRows(values)
+	self.table.Cursor = min(self.table.Cursor, max(len(self.rows)-1, 0))
+}
+
+/* Return bond under cursor, false if list is empty */
+func (self *BondsListView) Selected() (*bonds.BondsData, bool) {
+	if len(self.rows) == 0 {
+		return nil, false
+	}
+
+	return self.Source.Bonds[self.rows[self.table.Cursor]], true
+}
+
+/* Run command for bond under cursor, after it rebuild rows, because command can change bonds */
+func (self *BondsListView) runForSelected(command string) {
+	obj, exist := self.Selected()
+
+	if !exist {
+		return
+	}
+
+	ExecuteCommand(command + " " + obj.ID)
+	self.update()
+}
+
+/* Set sort key, change direction if key already used */
+func (self *BondsListView) SetSort(key string) {
+	if self.SortKey == key {
+		self.SortDesc = !self.SortDesc
+	} else {
+		self.SortKey = key
+		self.SortDesc = false
+	}
+
+	self.update()
+}
+
+/* Move cursor to next (step 1) or previous (step -1) match, starting from cursor itself if fromCursor */
+func (self *BondsListView) jumpToMatch(step int, fromCursor bool) bool {
+	count := len(self.rows)
+
+	if count == 0 {
+		return false
+	}
+
+	start := self.table.Cursor + step
+
+	if fromCursor {
+		start = self.table.Cursor
+	}
+
+	for ind := 0; ind < count; ind++ {
+		row := ((start+ind*step)%count + count) % count
+
+		if self.isMatch(row) {
+			self.table.Cursor = row
+			return true
+		}
+	}
+
+	return false
+}
+
+func (self *BondsListView) title() string {
+	if self.SortKey == "" {
+		return "|Bonds List|"
+	}
+
+	var direction string = "asc"
+
+	if self.SortDesc {
+		direction = "desc"
+	}
+
+	return fmt.Sprintf("|Bonds List: %s %s|", self.SortKey, direction)
+}
+
+func (self *BondsListView) statusLine() string {
+	if len(self.Query) == 0 {
+		return fmt.Sprintf(
+			"%s:details %s:delete %s:edit %s:copy %c-%c:sort %s:order %s:search",
+			ActiveKeymap.Name(ActionSelect), ActiveKeymap.Name(ActionDelete), ActiveKeymap.Name(ActionEdit), ActiveKeymap.Name(ActionCopy),
+			FirstSortKey, FirstSortKey+len(bonds.SortKeys)-1, ActiveKeymap.Name(ActionInsertionSort), ActiveKeymap.Name(ActionSearch),
+		)
+	}
+
+	var matches int
+
+	for row := range self.rows {
+		if self.isMatch(row) {
+			matches++
+		}
+	}
+
+	return fmt.Sprintf(
+		"%s%s  matches:%d %s/%s:next/prev",
+		ActiveKeymap.Name(ActionSearch), string(self.Query), matches, ActiveKeymap.Name(ActionNextMatch), ActiveKeymap.Name(ActionPrevMatch),
+	)
+}
+
+/* Area of table inside of window, status line is under it */
+func (self *BondsListView) tableArea() widget.Rect {
+	return widget.Rect{SizeY: self.window.SizeY - 3, SizeX: self.window.SizeX - 2, PosY: 1, PosX: 1}
+}
+
+/* Custom draw of window: table and status line */
+func (self *BondsListView) drawTable() {
+	area := self.tableArea()
+	self.window.SetTitle(self.title())
+	self.table.Draw(self.window.Window, area)
+	self.window.Window.MovePrint(area.PosY+area.SizeY, area.PosX, widget.Clip(self.statusLine(), area.SizeX))
+}
+
+/* Draw window immediately, used while reading search query outside of main loop */
+func (self *BondsListView) draw() {
+	self.window.Draw().DrawBox()
+	goncurses.Update()
+}
+
+/* Read search query char by char and jump to first match after each change */
+func (self *BondsListView) search() {
+	var text widget.TextDecoder
+	self.Query = nil
+	start := self.table.Cursor
+	self.window.Window.Timeout(-1) // Window has timeout of main loop
+
+	for {
+		self.draw()
+		key := self.window.Window.GetChar()
+
+		switch key {
+		case goncurses.KEY_ENTER, goncurses.KEY_RETURN:
+			return
+
+		case terminal.KeyEscape:
+			self.Query = nil
+			return
+
+		case goncurses.KEY_BACKSPACE, 127, 8:
+			if len(self.Query) > 0 {
+				self.Query = self.Query[:len(self.Query)-1]
+			}
+
+		default:
+			if char, isText := text.Decode(key); isText {
+				self.Query = append(self.Query, char)
+			}
+		}
+
+		self.table.Cursor = start
+		self.jumpToMatch(1, true)
+	}
+}
+
+/*
+Create window centered at parent and push it to Navigator, window closed by ActionExit
+Keys without own action and mouse are passed into table
+*/
+func (self *BondsListView) Open(parent Rect) error {
+	sizeY, sizeX := self.table.Size(parent.SizeY-3, parent.SizeX-2)
+	sizeX = max(sizeX, widget.Width(self.title()), widget.Width(self.statusLine()))
+	rect := widget.Center(widget.Rect(parent), sizeY+3, sizeX+2)
+	window, err := FSMWindowNew(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+
+	if err != nil {
+		return err
+	}
+
+	self.window = window
+	closeIf := func(result widget.Result) bool {
+		if result == widget.ResultClose {
+			Navigator.Close(window)
+		}
+
+		return true
+	}
+
+	runForSelected := func(command string) SpecialInputFunc {
+		return func() bool {
+			self.runForSelected(command)
+			return true
+		}
+	}
+
+	jumpToMatch := func(step int) SpecialInputFunc {
+		return func() bool {
+			self.jumpToMatch(step, false)
+			return true
+		}
+	}
+
+	window.SetTitle(self.title()).SetCustomDraw(self.drawTable).
+		RegisterAction(ActionEdit, runForSelected("edit")).
+		RegisterAction(ActionCopy, runForSelected("copy")).
+		RegisterAction(ActionDelete, func() bool {
+			obj, exist := self.Selected()
+
+			if !exist {
+				return true
+			}
+
+			err := PopUpConfirm(fmt.Sprintf("Delete '%s'?", obj.Name), self.window.Rect(), func(yes bool) {
+				if yes {
+					self.runForSelected("delete")
+				}
+			})
+
+			if err != nil {
+				Terminal.PrintError(err)
+			}
+
+			return true
+		}).
+		RegisterAction(ActionInsertionSort, func() bool {
+			self.SortKey = ""
+			self.update()
+			return true
+		}).
+		RegisterAction(ActionSearch, func() bool {
+			self.search()
+			return true
+		}).
+		RegisterAction(ActionNextMatch, jumpToMatch(1)).
+		RegisterAction(ActionPrevMatch, jumpToMatch(-1)).
+		RegisterDefaultInput(func(key goncurses.Key) bool {
+			return closeIf(self.table.Input(WidgetCommand(key, self.table), key))
+		}).
+		RegisterMouse(func(y, x int, button goncurses.MouseButton) bool {
+			wheel, clicks := WidgetMouse(button)
+			area := self.tableArea()
+			return closeIf(self.table.Mouse(y-area.PosY, x-area.PosX, wheel, clicks))
+		})
+
+	for ind, key := range bonds.SortKeys {
+		window.RegisterInput(goncurses.Key(FirstSortKey+ind), func() bool {
+			self.SetSort(key)
+			return true
+		})
+	}
+
+	Navigator.Push(window)
+	return nil
+}
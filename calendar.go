@@ -0,0 +1,170 @@
+/*
+Calendar window with month grid (Mon-Sun)
+Days with payments highlighted, payments of selected day listed in side panel
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"fmt"
+	"time"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	calendarCellWidth = 4
+	calendarGridWidth = calendarCellWidth*7 + 2 // Grid with left margin and separator
+)
+
+type CalendarView struct {
+	Window   *FSMWindow
+	Selected time.Time // Selected day, always at midnight UTC
+}
+
+/* Return today at midnight UTC, same as parsed pay dates */
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+/* Move date by months, day is clamped to last day of target month, so short months are not skipped */
+func AddMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(date.Day(), lastDay)-1)
+}
+
+func CalendarViewNew(sizeY, sizeX, posY, posX int) (*CalendarView, error) {
+	window, err := FSMWindowNew(sizeY, sizeX, posY, posX)
+
+	if err != nil {
+		return nil, err
+	}
+
+	view := new(CalendarView)
+	view.Window = window
+	view.Selected = today()
+	window.Window.Keypad(true)
+
+	moveDays := func(days int) SpecialInputFunc {
+		return func() bool {
+			view.Selected = view.Selected.AddDate(0, 0, days)
+			return true
+		}
+	}
+
+	moveMonths := func(months int) SpecialInputFunc {
+		return func() bool {
+			view.Selected = AddMonths(view.Selected, months)
+			return true
+		}
+	}
+
+	window.SetTitle("|Calendar|").SetCustomDraw(view.draw).
+		RegisterAction(ActionLeft, moveDays(-1)).
+		RegisterAction(ActionRight, moveDays(1)).
+		RegisterAction(ActionUp, moveDays(-7)).
+		RegisterAction(ActionDown, moveDays(7)).
+		RegisterAction(ActionPrev, moveMonths(-1)).
+		RegisterAction(ActionNext, moveMonths(1)).
+		RegisterAction(ActionToday, func() bool {
+			view.Selected = today()
+			return true
+		})
+
+	return view, nil
+}
+
+/* Draw month grid of selected day and side panel with payments of selected day */
+func (self *CalendarView) draw() {
+	win := self.Window.Window
+	obj := GraphBonds()
+	year, month := self.Selected.Year(), self.Selected.Month()
+	payments := obj.PaymentsByYearMonth(year, int(month))
+	paymentDays := make(map[int]bool)
+
+	for _, payment := range payments {
+		paymentDays[payment.Date.Day()] = true
+	}
+
+	var y int = 1
+	header := fmt.Sprintf("%s %d", month, year)
+	win.MovePrint(y, 1+(calendarGridWidth-len(header))/2, header)
+	y += 2
+
+	for ind, weekday := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		win.MovePrint(y, 2+ind*calendarCellWidth, weekday)
+	}
+
+	y++
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	column := (int(first.Weekday()) + 6) % 7 // Monday is first column
+
+	for day := first; day.Month() == month; day = day.AddDate(0, 0, 1) {
+		var attr goncurses.Char = goncurses.A_NORMAL
+
+		if paymentDays[day.Day()] {
+			attr = ActiveTheme.Match.Attr()
+		}
+
+		if day.Equal(self.Selected) {
+			attr = ActiveTheme.Cursor.Attr()
+		}
+
+		win.AttrOn(attr)
+		win.MovePrintf(y, 2+column*calendarCellWidth, "%2d", day.Day())
+		win.AttrOff(attr)
+
+		if paymentDays[day.Day()] {
+			win.MovePrint(y, 4+column*calendarCellWidth, "*")
+		}
+
+		column++
+
+		if column == 7 {
+			column = 0
+			y += 2
+		}
+	}
+
+	sizeY, sizeX := self.Window.SizeY, self.Window.SizeX
+	win.MovePrintf(sizeY-2, 1, "Month total: %.2f", bonds.PaymentsTotal(payments))
+	win.VLine(1, calendarGridWidth, goncurses.ACS_VLINE, sizeY-2)
+	self.drawDayPanel(payments, calendarGridWidth+2, sizeX-calendarGridWidth-3)
+}
+
+/* Draw list of selected day payments at column x with given width */
+func (self *CalendarView) drawDayPanel(payments []bonds.Payment, x, width int) {
+	win := self.Window.Window
+	var y int = 1
+	win.MovePrint(y, x, "Payments "+self.Selected.Format(DefaultDateLayout))
+	y += 2
+	dayPayments := make([]bonds.Payment, 0)
+
+	for _, payment := range payments {
+		if payment.Date.Day() == self.Selected.Day() {
+			dayPayments = append(dayPayments, payment)
+		}
+	}
+
+	if len(dayPayments) == 0 {
+		win.MovePrint(y, x, "No payments")
+		return
+	}
+
+	for _, payment := range dayPayments {
+		if y >= self.Window.SizeY-3 {
+			win.MovePrint(y, x, "...")
+			break
+		}
+
+		line := fmt.Sprintf("%-10s %10.2f %s", payment.Type, payment.Amount, payment.Bond.Name)
+		win.MovePrint(y, x, line[:min(len(line), width)])
+		y++
+	}
+
+	y++
+	win.MovePrintf(y, x, "Day total: %.2f", bonds.PaymentsTotal(dayPayments))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddMonths(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		date   time.Time
+		months int
+		want   time.Time
+	}{
+		{"same day", date(2027, time.January, 15), 1, date(2027, time.February, 15)},
+		{"clamped to short month", date(2027, time.January, 31), 1, date(2027, time.February, 28)},
+		{"clamped in leap year", date(2028, time.January, 30), 1, date(2028, time.February, 29)},
+		{"clamped backward", date(2027, time.March, 31), -1, date(2027, time.February, 28)},
+		{"thirty days month", date(2027, time.May, 31), 1, date(2027, time.June, 30)},
+		{"over year end", date(2026, time.December, 31), 1, date(2027, time.January, 31)},
+		{"back over year start", date(2027, time.January, 31), -2, date(2026, time.November, 30)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := AddMonths(test.date, test.months)
+
+			if !got.Equal(test.want) {
+				t.Errorf("AddMonths(%s, %d) = %s, want %s", test.date.Format(DefaultDateLayout), test.months, got.Format(DefaultDateLayout), test.want.Format(DefaultDateLayout))
+			}
+		})
+	}
+}
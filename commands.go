@@ -10,8 +10,9 @@ import (
 
 type CommandProcessing func([]string) error
 type Command struct {
-	Info     string // Help message for command
+	Info     string            // Help message for command
 	Executor CommandProcessing // func for execute command
+	Mutating bool              // Command change bonds, autosave will be scheduled after it
 }
 
 var (
@@ -41,7 +42,7 @@ func ExecuteCommand(input string) {
 	err := IsCommandExist(splitted[0])
 
 	if err != nil {
-		Terminal.Print(err.Error())
+		Terminal.PrintError(err)
 		return
 	}
 
@@ -50,7 +51,12 @@ func ExecuteCommand(input string) {
 	err = commandStruct.Executor(splitted[1:])
 
 	if err != nil {
-		Terminal.Print(err.Error())
+		Terminal.PrintError(err)
+		return
+	}
+
+	if commandStruct.Mutating {
+		AutosaveState.Touch()
 	}
 }
 
@@ -58,3 +64,21 @@ func ExecuteCommand(input string) {
 func RegisterCommand(name string, obj Command) {
 	CommandTable[name] = obj
 }
+
+/* Return first argument if given, otherwise ask user for it in terminal */
+func ArgOrAskString(args []string, question string) (string, error) {
+	if len(args) != 0 {
+		return args[0], nil
+	}
+
+	return Terminal.AskString(question)
+}
+
+/* Return all arguments joined by space if given, otherwise ask user in terminal, for values which may contain spaces */
+func ArgsOrAskString(args []string, question string) (string, error) {
+	if len(args) != 0 {
+		return strings.Join(args, " "), nil
+	}
+
+	return Terminal.AskString(question)
+}
@@ -0,0 +1,88 @@
+/*
+File for config directory
+Config directory defined by XDG base directory specification:
+    $XDG_CONFIG_HOME/bonds_calendar or $HOME/.config/bonds_calendar
+Config files are optional json files, defaults are used for not existing files
+Example of settings.json:
+    {"scrollback": 5000}
+*/
+package main
+
+import (
+	"bonds_payment_calendar/terminal"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	SettingsFile = "settings.json"
+)
+
+/* General settings, which are not colors or keys */
+type Settings struct {
+	Scrollback int `json:"scrollback"` // Count of messages kept in terminal for scrolling back
+}
+
+func DefaultSettings() *Settings {
+	return &Settings{Scrollback: terminal.DefaultScrollbackSize}
+}
+
+/* Load settings from config directory, return default settings if file not exist */
+func LoadSettings() (*Settings, error) {
+	settings := DefaultSettings()
+	_, err := LoadConfigFile(SettingsFile, settings)
+
+	if err != nil {
+		return DefaultSettings(), fmt.Errorf("Can't load settings: %s", err.Error())
+	}
+
+	if settings.Scrollback < 1 {
+		return DefaultSettings(), fmt.Errorf("Can't load settings: scrollback must be at least 1, got %d", settings.Scrollback)
+	}
+
+	return settings, nil
+}
+
+/* Return path to config directory, directory is not created */
+func ConfigDir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+
+	if base == "" {
+		home, err := os.UserHomeDir()
+
+		if err != nil {
+			return "", err
+		}
+
+		base = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(base, DataDirName), nil
+}
+
+/*
+Decode json config file with given name from config directory into obj
+Fields missing in file are left unchanged, so obj must be filled with defaults
+Return false without error if file not exist
+*/
+func LoadConfigFile(name string, obj any) (bool, error) {
+	dir, err := ConfigDir()
+
+	if err != nil {
+		return false, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, name))
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(content, obj)
+}
@@ -0,0 +1,278 @@
+/*
+Scaled graph of payment amounts by months of year
+Bars are scaled to window height, fractional part of bar drawn by unicode blocks
+Series are stacked: coupons and redemptions, or tags of bonds
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	GraphCountMode  = "count"  // One '+' per payment
+	GraphAmountMode = "amount" // Amounts stacked by coupons and redemptions
+	GraphTagsMode   = "tags"   // Amounts stacked by first tag of bond
+
+	graphAxisWidth   = 5 // Width of y axis labels
+	graphBarWidth    = 2
+	graphOtherSeries = "other"
+	graphUntagged    = "untagged"
+)
+
+var (
+	GraphModes = []string{GraphCountMode, GraphAmountMode, GraphTagsMode}
+
+	graphFills   = []string{"█", "▓", "▒", "░"}                    // Fill of full cells, one for each series
+	graphEighths = []string{"", "▁", "▂", "▃", "▄", "▅", "▆", "▇"} // Top cell of bar, by filled eighths
+
+	graphASCIIFills   = []string{"#", "%", "=", "-"} // Used if locale is not UTF-8
+	graphASCIIEighths = []string{"", ".", ".", ".", ":", ":", ":", ":"}
+)
+
+/* Check locale from environment, same order as setlocale uses */
+func unicodeSupported() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+
+	return false
+}
+
+/* Return glyphs for full cells and top cell of bar */
+func graphGlyphs() (fills, eighths []string) {
+	if unicodeSupported() {
+		return graphFills, graphEighths
+	}
+
+	return graphASCIIFills, graphASCIIEighths
+}
+
+/* X of first month column, columns placed with offsetX step */
+func GraphFirstColumn(mode string) int {
+	if mode == GraphCountMode {
+		return 4
+	}
+
+	return graphAxisWidth + 2
+}
+
+/* Return month of column at x, 0 if x is outside of columns */
+func GraphMonthAt(mode string, x, offsetX int) int {
+	first := GraphFirstColumn(mode)
+
+	if x < first || offsetX <= 0 {
+		return 0
+	}
+
+	month := (x-first)/offsetX + 1
+
+	if month > 12 {
+		return 0
+	}
+
+	return month
+}
+
+/* Amounts of one stacked series by months */
+type GraphSeries struct {
+	Name    string
+	Amounts [12]float64
+}
+
+/* Return name of series for payment in given graph mode */
+func graphSeriesName(mode string, payment bonds.Payment) string {
+	if mode != GraphTagsMode {
+		return payment.Type
+	}
+
+	if len(payment.Bond.Tags) == 0 {
+		return graphUntagged
+	}
+
+	return payment.Bond.Tags[0]
+}
+
+/*
+Split payments of year into series sorted by total amount
+Count of series is limited by count of fills, smallest series merged into graphOtherSeries
+*/
+func GraphSeriesByYear(obj *bonds.Bonds, year int, mode string) []GraphSeries {
+	byName := make(map[string]*GraphSeries)
+	totals := make(map[string]float64)
+
+	for m := 1; m < 13; m++ {
+		for _, payment := range obj.PaymentsByYearMonth(year, m) {
+			name := graphSeriesName(mode, payment)
+
+			if _, exist := byName[name]; !exist {
+				byName[name] = &GraphSeries{Name: name}
+			}
+
+			byName[name].Amounts[m-1] += payment.Amount
+			totals[name] += payment.Amount
+		}
+	}
+
+	result := make([]GraphSeries, 0, len(byName))
+
+	for _, series := range byName {
+		result = append(result, *series)
+	}
+
+	slices.SortFunc(result, func(first, second GraphSeries) int {
+		if totals[first.Name] != totals[second.Name] {
+			return cmp.Compare(totals[second.Name], totals[first.Name])
+		}
+
+		return cmp.Compare(first.Name, second.Name)
+	})
+
+	if len(result) > len(graphFills) {
+		other := GraphSeries{Name: graphOtherSeries}
+
+		for _, series := range result[len(graphFills)-1:] {
+			for ind, amount := range series.Amounts {
+				other.Amounts[ind] += amount
+			}
+		}
+
+		result = append(result[:len(graphFills)-1], other)
+	}
+
+	return result
+}
+
+/* Draw stacked bar from bottom row up, boundaries are cumulative heights of series in eighths of cell */
+func drawStackedBar(win *goncurses.Window, bottom, x int, boundaries []int) {
+	if len(boundaries) == 0 {
+		return
+	}
+
+	total := boundaries[len(boundaries)-1]
+	fills, eighths := graphGlyphs()
+
+	for cell := 0; cell*8 < total; cell++ {
+		var glyph string
+		var series int
+
+		for series < len(boundaries)-1 && boundaries[series] <= cell*8+4 {
+			series++
+		}
+
+		if filled := total - cell*8; filled < 8 {
+			glyph = eighths[filled]
+		} else {
+			glyph = fills[series]
+		}
+
+		win.AttrOn(ActiveTheme.SeriesAttr(series))
+
+		for dx := 0; dx < graphBarWidth; dx++ {
+			win.MovePrint(bottom-cell, x+dx, glyph)
+		}
+
+		win.AttrOff(ActiveTheme.SeriesAttr(series))
+	}
+}
+
+/* Draw amounts of payments for given year as scaled stacked bars, mode is GraphAmountMode or GraphTagsMode */
+func DrawAmountGraphByYear(obj *bonds.Bonds, year, selectedMonth int, mode string, win *goncurses.Window, sizeX, sizeY, offsetX int) YearInfo {
+	result := YearInfo{Year: year}
+	series := GraphSeriesByYear(obj, year, mode)
+
+	var legendY int = 1
+	var chartTop int = 2
+	var monthY int = sizeY - 1
+	var amountY int = sizeY - 3
+	var chartBottom int = amountY - 1
+	chartHeight := chartBottom - chartTop + 1
+
+	var totals [12]float64
+	var maxValue float64
+
+	for m := 0; m < 12; m++ {
+		for _, item := range series {
+			totals[m] += item.Amounts[m]
+		}
+
+		maxValue = max(maxValue, totals[m])
+		result.Amount += totals[m]
+		result.PaymentCount += obj.PayCountByYearMonth(year, m+1)
+	}
+
+	var x int = 1
+	fills, _ := graphGlyphs()
+
+	for ind, item := range series {
+		win.AttrOn(ActiveTheme.SeriesAttr(ind))
+		win.MovePrint(legendY, x, fills[ind])
+		win.AttrOff(ActiveTheme.SeriesAttr(ind))
+		win.MovePrint(legendY, x+2, item.Name)
+		x += len(item.Name) + 3
+	}
+
+	if len(series) == 0 {
+		win.MovePrint(legendY, x, "No amounts, set couponValue and faceValue of bonds")
+	}
+
+	win.MovePrint(monthY, 1, "M")
+	win.MovePrint(amountY, 1, "A")
+
+	if maxValue > 0 && chartHeight > 1 {
+		win.MovePrint(chartTop, 1, FormatCompact(maxValue))
+		win.MovePrint(chartTop+chartHeight/2, 1, FormatCompact(maxValue/2))
+		win.MovePrint(chartBottom, 1, "0")
+		win.VLine(chartTop, graphAxisWidth, goncurses.ACS_VLINE, chartHeight)
+	}
+
+	x = GraphFirstColumn(mode)
+
+	for m := 1; m < 13; m++ {
+		if m == selectedMonth {
+			ActiveTheme.Cursor.Print(win, monthY, x, fmt.Sprintf("%02d", m))
+		} else {
+			win.MovePrintf(monthY, x, "%02d", m)
+		}
+
+		if totals[m-1] > 0 {
+			win.MovePrint(amountY, x, FormatCompact(totals[m-1]))
+		}
+
+		if maxValue > 0 && chartHeight > 1 {
+			boundaries := make([]int, 0, len(series))
+			var cumulative float64
+
+			for _, item := range series {
+				cumulative += item.Amounts[m-1]
+				boundaries = append(boundaries, int(cumulative/maxValue*float64(chartHeight*8)+0.5))
+			}
+
+			if totals[m-1] > 0 {
+				boundaries[len(boundaries)-1] = max(boundaries[len(boundaries)-1], 1)
+			}
+
+			drawStackedBar(win, chartBottom, x, boundaries)
+		}
+
+		x += offsetX
+	}
+
+	total := fmt.Sprintf(":%s", FormatCompact(result.Amount))
+
+	if x-(offsetX/2)+len(total) < sizeX-1 {
+		win.MovePrint(amountY, x-(offsetX/2), total)
+	}
+
+	return result
+}
@@ -0,0 +1,349 @@
+/*
+Key bindings of UI
+Each action has name and list of keys, windows bind functions to actions instead of keys
+Keymap loaded from 'keymap.json' in config directory, actions missing in file keep default keys
+Example of keymap.json:
+    {"up": ["up", "k", "w"], "down": ["down", "j", "s"], "exit": ["q", "esc"]}
+Key names: single printable char, up, down, left, right, pgup, pgdn, home, end, enter, tab, esc, space, backspace, del, f1-f12
+Help window and status line are generated from active keymap
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"bonds_payment_calendar/terminal"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	KeymapFile = "keymap.json"
+)
+
+/* Names of actions, same names used in keymap.json */
+const (
+	ActionExit    = "exit"    // Exit from programm, close pop up, return focus or window to graph
+	ActionHelp    = "help"    // Show keys of active keymap
+	ActionCommand = "command" // Start typing command in terminal
+	ActionFocus   = "focus"   // Move focus to next pane
+	ActionLayout  = "layout"  // Switch to next layout
+
+	ActionUp     = "up" // Move cursor or scroll by one line
+	ActionDown   = "down"
+	ActionLeft   = "left"
+	ActionRight  = "right"
+	ActionPrev   = "prev" // Previous year, month or page, depends on window
+	ActionNext   = "next"
+	ActionSelect = "select" // Open details of selected item
+
+	ActionAllPortfolios = "all_portfolios"
+	ActionGraphMode     = "graph_mode"
+	ActionCalendar      = "calendar"
+	ActionTimeline      = "timeline"
+	ActionUndo          = "undo"
+	ActionRedo          = "redo"
+
+	ActionToday       = "today"
+	ActionGranularity = "granularity"
+
+	ActionSearch        = "search"
+	ActionNextMatch     = "next_match"
+	ActionPrevMatch     = "prev_match"
+	ActionDelete        = "delete"
+	ActionEdit          = "edit"
+	ActionCopy          = "copy"
+	ActionInsertionSort = "insertion_sort"
+)
+
+/* Line of help window: keys of all actions and description */
+type KeyHelp struct {
+	Actions []string
+	Info    string
+}
+
+/* Group of help lines for one window */
+type KeyHelpSection struct {
+	Title string
+	Keys  []KeyHelp
+}
+
+/* Keys of each action */
+type Keymap struct {
+	bindings map[string][]goncurses.Key
+}
+
+var (
+	keyNames = map[string]goncurses.Key{
+		"up":        goncurses.KEY_UP,
+		"down":      goncurses.KEY_DOWN,
+		"left":      goncurses.KEY_LEFT,
+		"right":     goncurses.KEY_RIGHT,
+		"pgup":      goncurses.KEY_PAGEUP,
+		"pgdn":      goncurses.KEY_PAGEDOWN,
+		"home":      goncurses.KEY_HOME,
+		"end":       goncurses.KEY_END,
+		"enter":     goncurses.KEY_RETURN,
+		"tab":       goncurses.KEY_TAB,
+		"esc":       terminal.KeyEscape,
+		"space":     ' ',
+		"backspace": goncurses.KEY_BACKSPACE,
+		"del":       goncurses.KEY_DC,
+	}
+
+	defaultBindings = map[string][]string{
+		ActionExit:    {"q"},
+		ActionHelp:    {"h", "?"},
+		ActionCommand: {":"},
+		ActionFocus:   {"tab"},
+		ActionLayout:  {"l"},
+
+		ActionUp:     {"up", "k"},
+		ActionDown:   {"down", "j"},
+		ActionLeft:   {"left"},
+		ActionRight:  {"right"},
+		ActionPrev:   {"<", "pgup"},
+		ActionNext:   {">", "pgdn"},
+		ActionSelect: {"enter"},
+
+		ActionAllPortfolios: {"a"},
+		ActionGraphMode:     {"m"},
+		ActionCalendar:      {"c"},
+		ActionTimeline:      {"t"},
+		ActionUndo:          {"u"},
+		ActionRedo:          {"r"},
+
+		ActionToday:       {"t"},
+		ActionGranularity: {"g"},
+
+		ActionSearch:        {"/"},
+		ActionNextMatch:     {"n"},
+		ActionPrevMatch:     {"N"},
+		ActionDelete:        {"d"},
+		ActionEdit:          {"e"},
+		ActionCopy:          {"c"},
+		ActionInsertionSort: {"0"},
+	}
+
+	KeyHelpSections = []KeyHelpSection{
+		{"Keys for all windows", []KeyHelp{
+			{[]string{ActionExit}, "Exit from programm, close sub-window or return to graph"},
+			{[]string{ActionHelp}, "Show this window"},
+			{[]string{ActionCommand}, "Start write command to terminal"},
+			{[]string{ActionFocus}, "Move focus to next pane: graph, info, terminal"},
+			{[]string{ActionLayout}, "Switch layout: " + strings.Join(Layouts.Names, ", ")},
+		}},
+		{"Keys for graph window", []KeyHelp{
+			{[]string{ActionPrev, ActionNext}, "Show previous/next year info"},
+			{[]string{ActionLeft, ActionRight}, "Select previous/next month"},
+			{[]string{ActionSelect}, "Show payments of selected month"},
+			{[]string{ActionAllPortfolios}, "Show graph for all portfolios or active one"},
+			{[]string{ActionGraphMode}, "Switch graph mode: " + strings.Join(GraphModes, ", ")},
+			{[]string{ActionCalendar}, "Open calendar"},
+			{[]string{ActionTimeline}, "Open timeline until last maturity"},
+			{[]string{ActionUndo}, "Undo last change of active portfolio"},
+			{[]string{ActionRedo}, "Redo last undone change of active portfolio"},
+		}},
+		{"Keys for terminal window", []KeyHelp{
+			{[]string{ActionSelect, ActionCommand}, "Start write command"},
+			{[]string{ActionUp, ActionDown}, "Scroll printed lines"},
+			{[]string{ActionPrev, ActionNext}, "Scroll printed lines by page"},
+		}},
+		{"Keys for calendar window", []KeyHelp{
+			{[]string{ActionLeft, ActionRight, ActionUp, ActionDown}, "Select day"},
+			{[]string{ActionPrev, ActionNext}, "Previous/next month"},
+			{[]string{ActionToday}, "Select today"},
+			{[]string{ActionCalendar}, "Back to graph"},
+		}},
+		{"Keys for timeline window", []KeyHelp{
+			{[]string{ActionLeft, ActionRight}, "Scroll by one period"},
+			{[]string{ActionPrev, ActionNext}, "Scroll by page"},
+			{[]string{ActionGranularity}, "Switch between quarters and years"},
+			{[]string{ActionTimeline}, "Back to graph"},
+		}},
+		{"Keys for lists", []KeyHelp{
+			{[]string{ActionUp, ActionDown}, "Move cursor or scroll"},
+			{[]string{ActionPrev, ActionNext}, "Scroll by page"},
+			{[]string{ActionSelect}, "Show payments of selected bond"},
+			{[]string{ActionEdit, ActionCopy, ActionDelete}, "Edit, copy or delete selected bond"},
+			{nil, fmt.Sprintf("%c-%c - Sort by %s, same key again changes direction",
+				FirstSortKey, FirstSortKey+len(bonds.SortKeys)-1, strings.Join(bonds.SortKeys, ", "))},
+			{[]string{ActionInsertionSort}, "Show bonds in order of appending"},
+			{[]string{ActionSearch}, "Search bonds, esc - cancel"},
+			{[]string{ActionNextMatch, ActionPrevMatch}, "Jump to next/previous match"},
+		}},
+	}
+
+	StatusKeys = []KeyHelp{
+		{[]string{ActionHelp}, "Help"},
+		{[]string{ActionExit}, "Exit"},
+		{[]string{ActionPrev}, "Prev year"},
+		{[]string{ActionNext}, "Next year"},
+	}
+
+	ActiveKeymap = DefaultKeymap()
+)
+
+/* Parse name of key from keymap.json */
+func ParseKeyName(name string) (goncurses.Key, error) {
+	if key, exist := keyNames[strings.ToLower(name)]; exist {
+		return key, nil
+	}
+
+	if len(name) == 1 && name[0] > ' ' && name[0] < 127 {
+		return goncurses.Key(name[0]), nil
+	}
+
+	var number int
+
+	if _, err := fmt.Sscanf(strings.ToLower(name), "f%d", &number); err == nil && number >= 1 && number <= 12 {
+		return goncurses.KEY_F1 + goncurses.Key(number-1), nil
+	}
+
+	return 0, fmt.Errorf("unknown key '%s'", name)
+}
+
+/* Return name of key, same as used in keymap.json */
+func KeyName(key goncurses.Key) string {
+	for name, value := range keyNames {
+		if value == key {
+			return name
+		}
+	}
+
+	if key >= goncurses.KEY_F1 && key < goncurses.KEY_F1+12 {
+		return fmt.Sprintf("f%d", key-goncurses.KEY_F1+1)
+	}
+
+	return string(rune(key))
+}
+
+/* Parse key names of actions, unknown actions and keys are errors */
+func parseBindings(bindings map[string][]string, result map[string][]goncurses.Key) error {
+	for action, names := range bindings {
+		if _, exist := defaultBindings[action]; !exist {
+			return fmt.Errorf("unknown action '%s'", action)
+		}
+
+		keys := make([]goncurses.Key, 0, len(names))
+
+		for _, name := range names {
+			key, err := ParseKeyName(name)
+
+			if err != nil {
+				return err
+			}
+
+			keys = append(keys, key)
+		}
+
+		result[action] = keys
+	}
+
+	return nil
+}
+
+func DefaultKeymap() *Keymap {
+	obj := new(Keymap)
+	obj.bindings = make(map[string][]goncurses.Key)
+
+	if err := parseBindings(defaultBindings, obj.bindings); err != nil {
+		panic(err)
+	}
+
+	return obj
+}
+
+/* Load keymap from config directory, return default keymap if file not exist */
+func LoadKeymap() (*Keymap, error) {
+	bindings := make(map[string][]string)
+	_, err := LoadConfigFile(KeymapFile, &bindings)
+
+	if err != nil {
+		return DefaultKeymap(), fmt.Errorf("Can't load keymap: %s", err.Error())
+	}
+
+	keymap := DefaultKeymap()
+	err = parseBindings(bindings, keymap.bindings)
+
+	if err != nil {
+		return DefaultKeymap(), fmt.Errorf("Can't load keymap: %s", err.Error())
+	}
+
+	return keymap, nil
+}
+
+/* Keys bound to action */
+func (self *Keymap) Keys(action string) []goncurses.Key {
+	return self.bindings[action]
+}
+
+/* Check is key bound to action */
+func (self *Keymap) Is(action string, key goncurses.Key) bool {
+	return slices.Contains(self.bindings[action], key)
+}
+
+/* Name of first key of action, empty if action has no keys */
+func (self *Keymap) Name(action string) string {
+	keys := self.bindings[action]
+
+	if len(keys) == 0 {
+		return ""
+	}
+
+	return KeyName(keys[0])
+}
+
+/* Names of all keys of action separated by '/' */
+func (self *Keymap) Names(action string) string {
+	names := make([]string, 0, len(self.bindings[action]))
+
+	for _, key := range self.bindings[action] {
+		names = append(names, KeyName(key))
+	}
+
+	return strings.Join(names, "/")
+}
+
+/* Lines of help window, generated from KeyHelpSections */
+func (self *Keymap) HelpLines() []string {
+	result := make([]string, 0)
+
+	for ind, section := range KeyHelpSections {
+		if ind != 0 {
+			result = append(result, "")
+		}
+
+		result = append(result, "  "+section.Title)
+
+		for _, help := range section.Keys {
+			if len(help.Actions) == 0 {
+				result = append(result, help.Info)
+				continue
+			}
+
+			keys := make([]string, 0, len(help.Actions))
+
+			for _, action := range help.Actions {
+				keys = append(keys, self.Names(action))
+			}
+
+			result = append(result, fmt.Sprintf("%s - %s", strings.Join(keys, ", "), help.Info))
+		}
+	}
+
+	return result
+}
+
+/* Status line with first key of each action from StatusKeys */
+func (self *Keymap) StatusLine() string {
+	parts := make([]string, 0, len(StatusKeys))
+
+	for _, help := range StatusKeys {
+		parts = append(parts, fmt.Sprintf("%s:%s", help.Info, self.Name(help.Actions[0])))
+	}
+
+	return strings.Join(parts, " ")
+}
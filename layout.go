@@ -0,0 +1,347 @@
+/*
+Declarative layout of panes on screen
+Layout is a tree of splits, each leaf is a named pane: main (graph, calendar, timeline), info and terminal
+Children of split share size by ratios, but not less than their minimal sizes
+Panes absent in active layout are hidden
+Last line of screen is status line, it is not a part of layout
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	PaneMain     = "main"
+	PaneInfo     = "info"
+	PaneTerminal = "terminal"
+
+	DefaultLayout = "default" // Graph at left, info and terminal at right
+	GraphLayout   = "graph"   // Graph on full screen, info and terminal hidden
+	StackedLayout = "stacked" // Graph and info at top, wide terminal at bottom
+
+	HiddenTerminalHeight = 8 // Height of hidden terminal, shown over bottom of screen while asking for input
+
+	ResizePollTimeout = 200 // Max wait of input in milliseconds, to notice terminal resize
+)
+
+/* Size and position of window */
+type Rect struct {
+	SizeY, SizeX int
+	PosY, PosX   int
+}
+
+/* Node of layout tree, leaf if Pane is not empty, split otherwise */
+type LayoutNode struct {
+	Pane       string
+	Vertical   bool    // Children of split placed from top to bottom, from left to right otherwise
+	Ratio      float64 // Share of size in parent split, relative to other children
+	MinY, MinX int     // Minimal size of leaf
+	Children   []*LayoutNode
+}
+
+/* Function which moves pane into rect, rect is empty if pane hidden */
+type PaneResizeFunc func(rect Rect, visible bool)
+
+type LayoutManager struct {
+	Names  []string // Names of layouts in order of registering
+	Active string
+
+	layouts      map[string]*LayoutNode
+	panes        map[string]PaneResizeFunc
+	rects        map[string]Rect // Rects of visible panes in active layout
+	sizeY, sizeX int             // Size of applied layout, zero if layout must be applied again
+}
+
+var (
+	Layouts = LayoutManagerNew().
+		Register(DefaultLayout, HSplit(1,
+			LeafNode(PaneMain, 2, 16, 54),
+			VSplit(1, LeafNode(PaneInfo, 1, 4, 20), LeafNode(PaneTerminal, 1, 6, 20)),
+		)).
+		Register(GraphLayout, LeafNode(PaneMain, 1, 16, 54)).
+		Register(StackedLayout, VSplit(1,
+			HSplit(2, LeafNode(PaneMain, 3, 16, 54), LeafNode(PaneInfo, 1, 4, 20)),
+			LeafNode(PaneTerminal, 1, 6, 40),
+		))
+)
+
+func LeafNode(pane string, ratio float64, minY, minX int) *LayoutNode {
+	return &LayoutNode{Pane: pane, Ratio: ratio, MinY: minY, MinX: minX}
+}
+
+/* Split placing children from left to right */
+func HSplit(ratio float64, children ...*LayoutNode) *LayoutNode {
+	return &LayoutNode{Ratio: ratio, Children: children}
+}
+
+/* Split placing children from top to bottom */
+func VSplit(ratio float64, children ...*LayoutNode) *LayoutNode {
+	return &LayoutNode{Vertical: true, Ratio: ratio, Children: children}
+}
+
+/* Minimal size of node: sum of children along split, max of children across it */
+func (self *LayoutNode) MinSize() (int, int) {
+	if self.Pane != "" {
+		return self.MinY, self.MinX
+	}
+
+	var minY, minX int
+
+	for _, child := range self.Children {
+		childY, childX := child.MinSize()
+
+		if self.Vertical {
+			minY += childY
+			minX = max(minX, childX)
+		} else {
+			minY = max(minY, childY)
+			minX += childX
+		}
+	}
+
+	return minY, minX
+}
+
+/*
+Split size between children by ratios
+Children which share is less than minimal size get minimal size, rest is split again between others
+*/
+func (self *LayoutNode) splitSizes(size int) []int {
+	result := make([]int, len(self.Children))
+	fixed := make([]bool, len(self.Children))
+
+	for changed := true; changed; {
+		changed = false
+		var ratios float64
+		var free int = size
+
+		for ind, child := range self.Children {
+			if fixed[ind] {
+				free -= result[ind]
+			} else {
+				ratios += child.Ratio
+			}
+		}
+
+		var cumulative float64
+		var placed int
+
+		for ind, child := range self.Children {
+			if fixed[ind] || ratios <= 0 {
+				continue
+			}
+
+			cumulative += child.Ratio
+			end := int(float64(free)*cumulative/ratios + 0.5)
+			result[ind] = end - placed
+			placed = end
+			minY, minX := child.MinSize()
+			minSize := minX
+
+			if self.Vertical {
+				minSize = minY
+			}
+
+			if result[ind] < minSize {
+				result[ind] = minSize
+				fixed[ind] = true
+				changed = true
+			}
+		}
+	}
+
+	return result
+}
+
+/* Calculate rects of all leaves placed in rect */
+func (self *LayoutNode) place(rect Rect, result map[string]Rect) {
+	if self.Pane != "" {
+		result[self.Pane] = rect
+		return
+	}
+
+	size := rect.SizeX
+
+	if self.Vertical {
+		size = rect.SizeY
+	}
+
+	var offset int
+
+	for ind, childSize := range self.splitSizes(size) {
+		childRect := Rect{rect.SizeY, childSize, rect.PosY, rect.PosX + offset}
+
+		if self.Vertical {
+			childRect = Rect{childSize, rect.SizeX, rect.PosY + offset, rect.PosX}
+		}
+
+		self.Children[ind].place(childRect, result)
+		offset += childSize
+	}
+}
+
+func LayoutManagerNew() *LayoutManager {
+	obj := new(LayoutManager)
+	obj.Names = make([]string, 0)
+	obj.layouts = make(map[string]*LayoutNode)
+	obj.panes = make(map[string]PaneResizeFunc)
+	obj.rects = make(map[string]Rect)
+
+	return obj
+}
+
+/* Add layout with given name, first registered layout becomes active */
+func (self *LayoutManager) Register(name string, root *LayoutNode) *LayoutManager {
+	if _, exist := self.layouts[name]; !exist {
+		self.Names = append(self.Names, name)
+	}
+
+	self.layouts[name] = root
+
+	if self.Active == "" {
+		self.Active = name
+	}
+
+	return self
+}
+
+/* Bind pane name with function, which moves pane on each applying of layout */
+func (self *LayoutManager) Attach(pane string, function PaneResizeFunc) *LayoutManager {
+	self.panes[pane] = function
+	return self
+}
+
+/* Make layout active, it will be applied on next Apply */
+func (self *LayoutManager) Switch(name string) error {
+	if _, exist := self.layouts[name]; !exist {
+		return fmt.Errorf("Unknown layout: '%s', layouts: %s", name, strings.Join(self.Names, ", "))
+	}
+
+	self.Active = name
+	self.sizeY, self.sizeX = 0, 0
+	return nil
+}
+
+/* Make active next layout in order of registering */
+func (self *LayoutManager) Next() {
+	ind := slices.Index(self.Names, self.Active)
+	self.Switch(self.Names[(ind+1)%len(self.Names)])
+}
+
+/* Mark layout as not applied, so next Apply moves panes even if size is same */
+func (self *LayoutManager) Invalidate() {
+	self.sizeY, self.sizeX = 0, 0
+}
+
+/* Minimal size of active layout */
+func (self *LayoutManager) MinSize() (int, int) {
+	return self.layouts[self.Active].MinSize()
+}
+
+/* Check is area big enough for active layout */
+func (self *LayoutManager) Fits(sizeY, sizeX int) bool {
+	minY, minX := self.MinSize()
+	return sizeY >= minY && sizeX >= minX
+}
+
+/*
+Place panes of active layout into area at top left corner of screen and call resize functions of all attached panes
+Nothing done if layout already applied for same size, return true if panes moved
+*/
+func (self *LayoutManager) Apply(sizeY, sizeX int) bool {
+	if sizeY == self.sizeY && sizeX == self.sizeX {
+		return false
+	}
+
+	self.sizeY, self.sizeX = sizeY, sizeX
+	self.rects = make(map[string]Rect)
+	self.layouts[self.Active].place(Rect{sizeY, sizeX, 0, 0}, self.rects)
+
+	for pane, function := range self.panes {
+		rect, visible := self.rects[pane]
+		function(rect, visible)
+	}
+
+	return true
+}
+
+/* Return rect of pane in applied layout, false if pane hidden */
+func (self *LayoutManager) Rect(pane string) (Rect, bool) {
+	rect, visible := self.rects[pane]
+	return rect, visible
+}
+
+/* Check is pane shown in applied layout */
+func (self *LayoutManager) IsVisible(pane string) bool {
+	_, visible := self.rects[pane]
+	return visible
+}
+
+/* Resize and move ncurses window to rect, content must be redrawn */
+func ResizeWindow(win *goncurses.Window, rect Rect) {
+	win.Resize(rect.SizeY, rect.SizeX)
+	win.MoveWindow(rect.PosY, rect.PosX)
+	win.Erase()
+}
+
+/*
+Go runtime handles SIGWINCH itself, so ncurses does not install own handler and getch never returns KEY_RESIZE
+Watcher receives signal and updates ncurses screen size by endwin and refresh, after it getch returns KEY_RESIZE
+*/
+type ResizeWatcher struct {
+	signals chan os.Signal
+}
+
+func ResizeWatcherNew() *ResizeWatcher {
+	obj := new(ResizeWatcher)
+	obj.signals = make(chan os.Signal, 1)
+	signal.Notify(obj.signals, syscall.SIGWINCH)
+
+	return obj
+}
+
+/* Update ncurses screen size if terminal resized since last call, must be called from goroutine of ncurses */
+func (self *ResizeWatcher) Check() bool {
+	select {
+	case <-self.signals:
+		goncurses.End()
+		goncurses.StdScr().Refresh()
+		return true
+
+	default:
+		return false
+	}
+}
+
+/* Limit input timeout by ResizePollTimeout, negative timeout means infinite wait */
+func (self *ResizeWatcher) Timeout(timeout int) int {
+	if timeout < 0 {
+		return ResizePollTimeout
+	}
+
+	return min(timeout, ResizePollTimeout)
+}
+
+/* Clear screen and show message about too small screen at center */
+func DrawScreenTooSmall(stdscr *goncurses.Window, maxY, maxX, minY, minX int) {
+	lines := []string{
+		"Screen is too small",
+		fmt.Sprintf("%dx%d, need %dx%d", maxX, maxY, minX, minY),
+	}
+
+	stdscr.Erase()
+
+	for ind, line := range lines {
+		stdscr.MovePrint(maxY/2-1+ind, max((maxX-len(line))/2, 0), line[:min(len(line), maxX)])
+	}
+
+	stdscr.Refresh()
+}
@@ -7,8 +7,11 @@ package main
 import (
 	"bonds_payment_calendar/bonds"
 	"bonds_payment_calendar/terminal"
+	"bonds_payment_calendar/widget"
 	"fmt"
-	"strconv"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gbin/goncurses"
@@ -18,27 +21,24 @@ import (
 type YearInfo struct {
 	Year         int
 	PaymentCount int
+	Amount       float64 // Sum of payments, calculated only by amount graph modes
 }
 
 var (
 	MaxX int
 	MaxY int
 
-	CurrentYear = time.Now().Year()
-	Terminal    = terminal.TerminalNew()
-	AllBonds    = bonds.BondsNew()
+	CurrentYear       = time.Now().Year()
+	Terminal          = terminal.TerminalNew()
+	Portfolios        = bonds.PortfoliosNew(DefaultPortfolioName)
+	AllBonds          = Portfolios.Current() // Active portfolio
+	ShowAllPortfolios = false                // Draw graph for all portfolios instead of active one
+	ActiveFilter      *bonds.Filter          // Filter for graph and list, nil for show all bonds
 )
 
 const (
-	ExitKey           = 'q'
-	HelpKey           = 'h'
-	IncreaseYearKey   = '>'
-	DecreaseYearKey   = '<'
-	ScrollUpKey       = 'w'
-	ScrollDownKey     = 's'
-	StartOfCommandKey = ':'
-
-	DefaultDateLayout = "02.01.2006"
+	DefaultDateLayout    = bonds.DateLayout
+	DefaultPortfolioName = "default"
 )
 
 func CommandHelp(args []string) error {
@@ -70,10 +70,34 @@ func CommandHelp(args []string) error {
 }
 
 func CommandList(args []string) error {
-	err := DrawListBonds(AllBonds, MaxY-1, 0, 0)
+	filter, err := FilterFromArgs(args)
+
+	if err != nil {
+		return err
+	}
+
+	err = DrawListBonds(AllBonds, filter, ScreenRect())
 	return err
 }
 
+func CommandFilter(args []string) error {
+	filter, err := bonds.ParseFilter(strings.Join(args, " "))
+
+	if err != nil {
+		return err
+	}
+
+	ActiveFilter = filter
+
+	if filter == nil {
+		Terminal.Print("Filter cleared")
+	} else {
+		Terminal.Print(fmt.Sprintf("Filter: '%s'", filter.Expression))
+	}
+
+	return nil
+}
+
 func CommandLoad(args []string) error {
 	var filename string
 
@@ -90,12 +114,18 @@ func CommandLoad(args []string) error {
 		filename = args[0]
 	}
 
-	err := AllBonds.LoadFromFile(filename)
+	if !AskDiscardChanges(AllBonds) {
+		Terminal.Print("Load canceled")
+		return nil
+	}
+
+	broken, err := AllBonds.LoadFromFile(filename)
 
 	if err != nil {
 		return err
 	}
 
+	PrintBrokenEntries(broken, true)
 	Terminal.Print(fmt.Sprintf("Loaded: %d bonds", len(AllBonds.Bonds)))
 
 	return err
@@ -117,6 +147,24 @@ func CommandSave(args []string) error {
 		filename = args[0]
 	}
 
+	if len(args) > 1 {
+		filter, err := bonds.ParseFilter(strings.Join(args[1:], " "))
+
+		if err != nil {
+			return err
+		}
+
+		filtered := AllBonds.Filter(filter)
+		err = filtered.SaveToFile(filename)
+
+		if err != nil {
+			return err
+		}
+
+		Terminal.Print(fmt.Sprintf("Saved: %d of %d bonds", len(filtered.Bonds), len(AllBonds.Bonds)))
+		return nil
+	}
+
 	err := AllBonds.SaveToFile(filename)
 
 	if err != nil {
@@ -128,63 +176,566 @@ func CommandSave(args []string) error {
 	return err
 }
 
-func CommandNewBonds(args []string) error {
-	data, err := CreateBondsByUser()
+func CommandDiff(args []string) error {
+	filename, err := ArgOrAskString(args, "Filename for compare:")
 
 	if err != nil {
 		return err
+	}
+
+	other := bonds.BondsNew()
+	broken, err := other.LoadFromFile(filename)
+
+	if err != nil {
+		return err
+	}
+
+	PrintBrokenEntries(broken, false)
+
+	diff := AllBonds.Diff(other)
+
+	if diff.IsEmpty() {
+		Terminal.Print("No differences")
+		return nil
+	}
+
+	return PopUpText("|Diff|", ScreenRect(), FormatBondsDiff(diff)...)
+}
+
+func CommandMerge(args []string) error {
+	filename, err := ArgOrAskString(args, "Filename for merge:")
+
+	if err != nil {
+		return err
+	}
+
+	other := bonds.BondsNew()
+	broken, err := other.LoadFromFile(filename)
+
+	if err != nil {
+		return err
+	}
+
+	PrintBrokenEntries(broken, false)
+
+	diff := AllBonds.Diff(other)
 
+	if diff.IsEmpty() {
+		Terminal.Print("No differences")
+		return nil
 	}
 
-	AllBonds.Append(data)
+	Terminal.Print(fmt.Sprintf("New: %d, only local: %d, conflicts: %d", len(diff.Added), len(diff.Removed), len(diff.Changed)))
+	takeOther := make(map[*bonds.BondsData]map[string]bool) // Fields taken from other by local bond
+
+	for _, conflict := range diff.Changed {
+		Terminal.Print(fmt.Sprintf("Conflict: '%s', %d fields", conflict.Local.Name, len(conflict.Changes)))
+		takeOther[conflict.Local] = make(map[string]bool)
+
+		for _, change := range conflict.Changes {
+			Terminal.Print(fmt.Sprintf("  %s: '%s' -> '%s'", change.Field, change.OldValue, change.NewValue))
+			answer := Terminal.AskChar("Keep [l]ocal or take [o]ther value?")
+
+			if ActiveKeymap.Is(ActionExit, answer) {
+				Terminal.Print("Merge canceled")
+				return nil
+			}
+
+			takeOther[conflict.Local][change.Field] = answer == 'o' || answer == 'O'
+		}
+	}
+
+	answer := Terminal.AskChar("Apply merge?[y/n]")
+
+	if answer != 'y' && answer != 'Y' {
+		Terminal.Print("Merge canceled")
+		return nil
+	}
+
+	AllBonds.Merge(diff, func(conflict bonds.BondsConflict, change bonds.FieldChange) bool {
+		return takeOther[conflict.Local][change.Field]
+	})
+	Terminal.Print(fmt.Sprintf("Merged: %d bonds", len(AllBonds.Bonds)))
+
 	return nil
 }
 
-func CommandDelete(args []string) error {
-	var index int
+func CommandPortfolio(args []string) error {
 	var err error
+	var name string
+	var subcommand string = "list"
+
+	if len(args) != 0 {
+		subcommand = args[0]
+		args = args[1:]
+	}
+
+	switch subcommand {
+	case "list":
+		for _, portfolioName := range Portfolios.Names {
+			var marker string = " "
+
+			if portfolioName == Portfolios.Active {
+				marker = "*"
+			}
+
+			Terminal.Print(fmt.Sprintf("%s %s - %d bonds", marker, portfolioName, len(Portfolios.Items[portfolioName].Bonds)))
+		}
+
+		for _, portfolioName := range ClosedPortfolioNames() {
+			Terminal.Print(fmt.Sprintf("  %s - saved, not opened", portfolioName))
+		}
+
+	case "new":
+		name, err = ArgOrAskString(args, "Portfolio name:")
+
+		if err != nil {
+			return err
+		}
+
+		err = CheckPortfolioNotSaved(name)
+
+		if err != nil {
+			return err
+		}
+
+		err = Portfolios.Create(name)
+
+		if err != nil {
+			return err
+		}
+
+		err = Portfolios.Switch(name)
+
+	case "switch":
+		name, err = ArgOrAskString(args, "Portfolio name:")
+
+		if err != nil {
+			return err
+		}
+
+		_, exist := Portfolios.Items[name]
+
+		if !exist && AutosaveState.Enabled && IsPortfolioSaved(name) {
+			var broken []bonds.BrokenEntry
+			broken, err = OpenSavedPortfolio(name)
+
+			if err != nil {
+				return err
+			}
+
+			PrintBrokenEntries(broken, true)
+			Terminal.Print(fmt.Sprintf("Opened saved portfolio '%s': %d bonds", name, len(Portfolios.Items[name].Bonds)))
+		}
+
+		err = Portfolios.Switch(name)
+
+	case "rename":
+		var oldName string = Portfolios.Active
+
+		if len(args) > 1 {
+			oldName = args[0]
+			args = args[1:]
+		}
+
+		name, err = ArgOrAskString(args, "New portfolio name:")
+
+		if err != nil {
+			return err
+		}
+
+		err = CheckPortfolioNotSaved(name)
+
+		if err != nil {
+			return err
+		}
+
+		err = Portfolios.Rename(oldName, name)
+
+		if err == nil && AutosaveState.Enabled {
+			err = RenamePortfolioFile(oldName, name)
+		}
+
+	case "close":
+		name = Portfolios.Active
+
+		if len(args) != 0 {
+			name = args[0]
+		}
+
+		obj, exist := Portfolios.Items[name]
+
+		if exist && !AskDiscardChanges(obj) {
+			Terminal.Print("Close canceled")
+			return nil
+		}
+
+		err = Portfolios.Close(name)
+
+	case "all":
+		ShowAllPortfolios = !ShowAllPortfolios
+
+	default:
+		err = fmt.Errorf("Unknown portfolio command: '%s'", subcommand)
+	}
+
+	AllBonds = Portfolios.Current()
+	return err
+}
+
+/* Return error if portfolio with given name is saved in data directory, new or renamed portfolio would overwrite it */
+func CheckPortfolioNotSaved(name string) error {
+	_, exist := Portfolios.Items[name]
+
+	if !exist && AutosaveState.Enabled && IsPortfolioSaved(name) {
+		return fmt.Errorf("Portfolio '%s' is saved in data directory, use 'portfolio switch %s' to open it", name, name)
+	}
+
+	return nil
+}
+
+/* Names of portfolios saved in data directory, but not opened */
+func ClosedPortfolioNames() []string {
+	result := make([]string, 0)
+
+	if !AutosaveState.Enabled {
+		return result
+	}
+
+	dir, err := DataDir()
+
+	if err != nil {
+		return result
+	}
+
+	names, _ := SavedPortfolioNames(dir)
+
+	for _, name := range names {
+		if _, exist := Portfolios.Items[name]; !exist {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+func CommandUndo(args []string) error {
+	description, err := AllBonds.Undo()
+
+	if err != nil {
+		return err
+	}
+
+	Terminal.Print(fmt.Sprintf("Undone: %s", description))
+	return nil
+}
+
+func CommandRedo(args []string) error {
+	description, err := AllBonds.Redo()
+
+	if err != nil {
+		return err
+	}
+
+	Terminal.Print(fmt.Sprintf("Redone: %s", description))
+	return nil
+}
+
+func CommandHistory(args []string) error {
+	done, undone := AllBonds.History.Descriptions()
+
+	if len(done) == 0 && len(undone) == 0 {
+		Terminal.Print("History is empty")
+		return nil
+	}
+
+	lines := make([]string, 0, len(done)+len(undone))
+
+	for ind, description := range done {
+		lines = append(lines, fmt.Sprintf("%d. %s", ind+1, description))
+	}
+
+	for _, description := range undone {
+		lines = append(lines, fmt.Sprintf("   %s (undone)", description))
+	}
+
+	return PopUpText("|History|", ScreenRect(), lines...)
+}
+
+func CommandLog(args []string) error {
+	var filename string
 
 	if len(args) == 0 {
-		tmp, err := Terminal.AskInt("Index for delete:")
+		input, err := Terminal.AskString("Filename for log:")
 
 		if err != nil {
 			return err
 		}
 
-		index = tmp
+		filename = input
 
 	} else {
-		tmp, err := strconv.Atoi(args[0])
+		filename = args[0]
+	}
+
+	lines := Terminal.Scrollback()
+	err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+
+	if err != nil {
+		return err
+	}
+
+	Terminal.Print(fmt.Sprintf("Saved: %d lines of terminal into %s", len(lines), filename))
+	return nil
+}
+
+func CommandLayout(args []string) error {
+	if len(args) == 0 {
+		for _, name := range Layouts.Names {
+			var marker string = " "
+
+			if name == Layouts.Active {
+				marker = "*"
+			}
+
+			Terminal.Print(fmt.Sprintf("%s %s", marker, name))
+		}
+
+		return nil
+	}
+
+	return Layouts.Switch(args[0])
+}
+
+func CommandEdit(args []string) error {
+	index, err := AskBond(args[:min(len(args), 1)], "Bond for edit (id, isin, name or #index):")
+
+	if err != nil {
+		return err
+	}
+
+	edited := *AllBonds.Bonds[index]
+	fields := bonds.EditableFields
+
+	if len(args) > 1 {
+		fields = args[1:2]
+	}
+
+	if len(args) > 2 {
+		err = edited.SetField(args[1], strings.Join(args[2:], " "))
 
 		if err != nil {
 			return err
 		}
 
-		index = tmp
+	} else {
+		Terminal.Print(fmt.Sprintf("***Bonds Edit: %s***", edited.Name))
+
+		for _, field := range fields {
+			value, err := edited.FieldValue(field)
+
+			if err != nil {
+				return err
+			}
+
+			value, err = Terminal.AskStringDefault(field+": ", value)
+
+			if err != nil {
+				return err
+			}
+
+			err = edited.SetField(field, value)
+
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	var obj *bonds.BondsData
-	var exist bool
+	err = edited.Validate()
 
-	if index < len(AllBonds.Bonds) && index >= 0 {
-		obj = AllBonds.Bonds[index]
-		exist = true
+	if err != nil {
+		return err
 	}
 
-	AllBonds.Bonds, err = SliceRemoveByIndex(AllBonds.Bonds, index)
+	err = AllBonds.Replace(index, &edited)
 
-	if exist && err == nil {
-		Terminal.Print(fmt.Sprintf("%d. %s - deleted", index, obj.Name))
+	if err != nil {
+		return err
 	}
 
-	return err
+	Terminal.Print(fmt.Sprintf("[%s] %s - edited", edited.ID, edited.Name))
+	return nil
+}
+
+func CommandCopy(args []string) error {
+	index, err := AskBond(args, "Bond for copy (id, isin, name or #index):")
+
+	if err != nil {
+		return err
+	}
+
+	obj, err := AllBonds.Copy(index)
+
+	if err != nil {
+		return err
+	}
+
+	Terminal.Print(fmt.Sprintf("[%s] %s - created", obj.ID, obj.Name))
+	return nil
+}
+
+func CommandShow(args []string) error {
+	index, err := AskBond(args, "Bond for show (id, isin, name or #index):")
+
+	if err != nil {
+		return err
+	}
+
+	return BondDetailsViewNew(AllBonds.Bonds[index]).Open(ScreenRect())
+}
+
+func CommandNewBonds(args []string) error {
+	return BondFormViewNew().Open()
+}
+
+func CommandDelete(args []string) error {
+	index, err := AskBond(args, "Bond for delete (id, isin, name or #index):")
+
+	if err != nil {
+		return err
+	}
+
+	obj, err := AllBonds.Remove(index)
+
+	if err != nil {
+		return err
+	}
+
+	Terminal.Print(fmt.Sprintf("[%s] %s - deleted", obj.ID, obj.Name))
+	return nil
+}
+
+/*
+Ask bond by id, isin, name prefix or '#index' if it not given in args and return it index
+All args are joined into one query, so name prefix may contain spaces
+If several bonds matched, ask user to choose one of them
+*/
+func AskBond(args []string, question string) (int, error) {
+	query, err := ArgsOrAskString(args, question)
+
+	if err != nil {
+		return -1, err
+	}
+
+	matches := AllBonds.Lookup(query)
+
+	switch len(matches) {
+	case 0:
+		return -1, fmt.Errorf("No bonds found by: '%s'", query)
+
+	case 1:
+		return matches[0], nil
+	}
+
+	Terminal.Print(fmt.Sprintf("Found %d bonds by '%s':", len(matches), query))
+
+	for num, index := range matches {
+		obj := AllBonds.Bonds[index]
+		Terminal.Print(fmt.Sprintf("%d. [%s] %s %s", num+1, obj.ID, obj.Name, obj.ISIN))
+	}
+
+	choice, err := Terminal.AskInt("Choose number:")
+
+	if err != nil {
+		return -1, err
+	}
+
+	if choice < 1 || choice > len(matches) {
+		return -1, fmt.Errorf("Wrong number: %d", choice)
+	}
+
+	return matches[choice-1], nil
+}
+
+/* Print entries skipped while loading from file, kept means entries will be saved back into file */
+func PrintBrokenEntries(broken []bonds.BrokenEntry, kept bool) {
+	if len(broken) == 0 {
+		return
+	}
+
+	if kept {
+		Terminal.Print(fmt.Sprintf("Invalid entries not used, but kept in file unchanged: %d", len(broken)))
+	} else {
+		Terminal.Print(fmt.Sprintf("Skipped invalid entries: %d", len(broken)))
+	}
+
+	for _, entry := range broken {
+		Terminal.PrintError(entry)
+	}
+}
+
+/* Return bonds which must be shown in graph - active portfolio or all portfolios */
+func GraphBonds() *bonds.Bonds {
+	if ShowAllPortfolios {
+		return Portfolios.Aggregated().Filter(ActiveFilter)
+	}
+
+	return AllBonds.Filter(ActiveFilter)
+}
+
+/* Parse filter from args, return active filter if args are empty */
+func FilterFromArgs(args []string) (*bonds.Filter, error) {
+	if len(args) == 0 {
+		return ActiveFilter, nil
+	}
+
+	return bonds.ParseFilter(strings.Join(args, " "))
+}
+
+/* Ask user for confirm discarding unsaved changes, return true without asking if bonds not changed */
+func AskDiscardChanges(obj *bonds.Bonds) bool {
+	if !obj.IsDirty() {
+		return true
+	}
+
+	answer := Terminal.AskChar("Unsaved changes will be lost. Continue?[y/n]")
+	return answer == 'y' || answer == 'Y'
+}
+
+/* Return '*' if bonds changed after last saving, empty string otherwise */
+func DirtyMarker(isDirty bool) string {
+	if isDirty {
+		return "*"
+	}
+
+	return ""
+}
+
+/* Title for main window with name of shown portfolio, unsaved changes marker and active filter */
+func MainTitle() string {
+	var filter string
+
+	if ActiveFilter != nil {
+		filter = fmt.Sprintf(" [%s]", ActiveFilter.Expression)
+	}
+
+	if ShowAllPortfolios {
+		return fmt.Sprintf("|Main: all portfolios%s%s|", DirtyMarker(Portfolios.IsDirty()), filter)
+	}
+
+	return fmt.Sprintf("|Main: %s%s%s|", Portfolios.Active, DirtyMarker(AllBonds.IsDirty()), filter)
 }
 
 /*
 Draw graph of payments for given year
 Called by main.Draw
 */
-func DrawGraphByYear(obj *bonds.Bonds, year int, win *goncurses.Window, sizeX, sizeY, offsetX int) YearInfo {
+func DrawGraphByYear(obj *bonds.Bonds, year, selectedMonth int, mode string, win *goncurses.Window, sizeX, sizeY, offsetX int) YearInfo {
+	if mode != GraphCountMode {
+		return DrawAmountGraphByYear(obj, year, selectedMonth, mode, win, sizeX, sizeY, offsetX)
+	}
+
 	result := YearInfo{
 		Year:         year,
 		PaymentCount: 0,
@@ -195,15 +746,22 @@ func DrawGraphByYear(obj *bonds.Bonds, year int, win *goncurses.Window, sizeX, s
 	var countY int = sizeY - 3
 	win.MovePrint(monthY, x, "M")
 	win.MovePrint(countY, x, "C")
-	x += 3
+	x = GraphFirstColumn(mode)
 
 	for m := 1; m < 13; m++ {
-		win.MovePrintf(monthY, x, "%02d", m)
+		if m == selectedMonth {
+			ActiveTheme.Cursor.Print(win, monthY, x, fmt.Sprintf("%02d", m))
+		} else {
+			win.MovePrintf(monthY, x, "%02d", m)
+		}
+
 		payCount := obj.PayCountByYearMonth(year, m)
 		result.PaymentCount += payCount
 		win.MovePrintf(countY, x, "%2d", payCount)
 		var graphY int = countY - 1
 
+		win.AttrOn(ActiveTheme.SeriesAttr(0))
+
 		for count := 0; count < payCount; count++ {
 			win.MovePrint(graphY, x+1, "+")
 			graphY--
@@ -213,6 +771,7 @@ func DrawGraphByYear(obj *bonds.Bonds, year int, win *goncurses.Window, sizeX, s
 			}
 		}
 
+		win.AttrOff(ActiveTheme.SeriesAttr(0))
 		x += offsetX
 	}
 
@@ -226,89 +785,86 @@ Called by independent sub-window - info
 */
 func DrawInfoByYear(win *goncurses.Window, sizeX, sizeY int, yearInfo YearInfo) {
 	var y int = 1
-	win.MovePrint(0, sizeX/2-2, "|Info|")
 	win.MovePrintf(y, sizeX/3, "Year: %d", yearInfo.Year)
 	y++
 	win.MovePrintf(y, 1, "Payments count: %d", yearInfo.PaymentCount)
 	y++
-}
 
-/* Draw a list of all bonds as scrollable pop up window */
-func DrawListBonds(bondsArr *bonds.Bonds, sizeY, posY, posX int) error {
-	bondsTable := make([]string, 0, len(bondsArr.Bonds))
-	var format string = "%d. Name:'%s' Coupon remaining:'%d', Near payday:(%02d.%02d.%d), ~PeriodDays(%d)"
+	if yearInfo.Amount != 0 {
+		win.MovePrintf(y, 1, "Payments amount: %.2f", yearInfo.Amount)
+		y++
+	}
+}
 
-	for id, obj := range bondsArr.Bonds {
+/* Draw a list of bonds matched by filter as scrollable pop up window, indices are same as in full list */
+func DrawListBonds(bondsArr *bonds.Bonds, filter *bonds.Filter, parent Rect) error {
+	return BondsListViewNew(bondsArr, filter).Open(parent)
+}
 
-		tmp := fmt.Sprintf(
-			format,
-			id,
-			obj.Name,
-			obj.CouponCount,
-			obj.CouponNearPayDate.Day(),
-			obj.CouponNearPayDate.Month(),
-			obj.CouponNearPayDate.Year(),
-			obj.CouponPeriod,
-		)
-		bondsTable = append(bondsTable, tmp)
+/*
+Draw all payments of given month with days, amounts and total as table in pop up window
+Selecting payment opens details of its bond
+*/
+func DrawMonthPayments(obj *bonds.Bonds, year, month int, parent Rect) error {
+	payments := obj.PaymentsByYearMonth(year, month)
+	rows := make([][]string, 0, len(payments))
+
+	for _, payment := range payments {
+		rows = append(rows, []string{
+			fmt.Sprintf("%02d", payment.Date.Day()),
+			payment.Bond.Name,
+			payment.Type,
+			fmt.Sprintf("%.2f", payment.Amount),
+		})
 	}
 
-	return PopUpScrollableList(bondsTable, "|Bonds List|", sizeY, posY, posX)
-}
+	columns := []widget.Column{{Title: "Day"}, {Title: "Name", Width: 24}, {Title: "Type", Width: 10}, {Title: "Amount", Width: 12, Right: true}}
+	table := widget.TableNew(columns, rows, true)
+	table.Footer = fmt.Sprintf("Total: %.2f in %d payments", bonds.PaymentsTotal(payments), len(payments))
+	table.OnSelect = func(ind int) widget.Result {
+		err := BondDetailsViewNew(payments[ind].Bond).Open(ScreenRect())
 
-/* Ask user for bonds params and create new one */
-func CreateBondsByUser() (*bonds.BondsData, error) {
-	Terminal.Print("***Bonds Create***")
-	name, err := Terminal.AskString("Name: ")
+		if err != nil {
+			Terminal.PrintError(err)
+		}
 
-	if err != nil {
-		return nil, err
+		return widget.ResultNone
 	}
 
-	couponCount, err := Terminal.AskInt("Coupons count: ")
+	_, err := PopUpWidget(table, fmt.Sprintf("|Payments %02d.%d|", month, year), parent)
+	return err
+}
+
+/* Convert diff into lines for show: '+' - new, '-' - only local, '~' - changed */
+func FormatBondsDiff(diff bonds.BondsDiff) []string {
+	result := make([]string, 0)
 
-	if err != nil {
-		return nil, err
+	for _, obj := range diff.Added {
+		result = append(result, fmt.Sprintf("+ %s", obj.Name))
 	}
 
-	couponNearestPayDate, err := Terminal.AskDate("Nearest pay day[dd.mm.yyyy]: ", DefaultDateLayout)
-
-	if err != nil {
-		return nil, err
+	for _, obj := range diff.Removed {
+		result = append(result, fmt.Sprintf("- %s", obj.Name))
 	}
 
-	couponNextPayDate, err := Terminal.AskDate("Next pay day[dd.mm.yyyy](can be empty): ", DefaultDateLayout)
-	Terminal.Print("******************")
+	for _, conflict := range diff.Changed {
+		result = append(result, fmt.Sprintf("~ %s", conflict.Local.Name))
 
-	if err != nil {
-		if couponCount == 1 {
-			couponNextPayDate = time.Time{}
-		} else {
-			return nil, err
+		for _, change := range conflict.Changes {
+			result = append(result, fmt.Sprintf("    %s: '%s' -> '%s'", change.Field, change.OldValue, change.NewValue))
 		}
 	}
 
-	result := bonds.BondsDataNew()
-	result.Name = name
-	result.CouponCount = couponCount
-	result.CouponPeriod = bonds.CouponPeriodCreate(couponNearestPayDate, couponNextPayDate)
-	result.CouponNearPayDate = couponNearestPayDate
-	return result, nil
+	return result
 }
 
 // TODO:
 // [ ] add more info in BoundsData
 
-// TODO:
-// [1/2] by key save bonds data in json in default path
-// [ ] Create way to init default path and save into it
-//      for now - ask for path and save into it
-
-// TODO:
-// new command 'dates <index>' - show all pay dates for bonds
-
 func main() {
 	var year int = CurrentYear
+	var month int = int(time.Now().Month())
+	var graphMode string = GraphCountMode
 
 	stdscr, err := goncurses.Init()
 	goncurses.Echo(false)
@@ -320,22 +876,55 @@ func main() {
 
 	defer goncurses.End()
 	MaxY, MaxX = goncurses.StdScr().MaxYX()
+	theme, themeErr := LoadTheme()
+	ActiveTheme = theme
+	colorsErr := ActiveTheme.Init() // Theme is usable even with error, failed roles are monochrome
 
-	mainHeight, mainWidth := MaxY-1, (MaxX/3)*2
-	mainPosY, mainPosX := 0, 0
-	main, err := FSMWindowNew(mainHeight, mainWidth, mainPosY, mainPosX)
+	widget.ActiveStyle = ActiveTheme.WidgetStyle()
+
+	keymap, keymapErr := LoadKeymap() // Must be loaded before windows bind actions
+	ActiveKeymap = keymap
+	settings, settingsErr := LoadSettings()
+	MouseInit()
+	Layouts.Apply(MaxY-1, MaxX) // Panes are not attached yet, only calculate rects for creating windows
+	mainRect, _ := Layouts.Rect(PaneMain)
+	main, err := FSMWindowNew(mainRect.SizeY, mainRect.SizeX, mainRect.PosY, mainRect.PosX)
 
 	if err != nil {
 		panic(err)
 	}
 
 	defer main.FreeWindow()
-	var graphOffsetX int = (mainWidth - 6) / 12
-	var focus *FSMWindow = main
-	main.SetTitle("|Main|").RegisterInput(IncreaseYearKey, func() bool {
+	main.Window.Keypad(true)
+
+	/* Read command in terminal and execute it */
+	askCommand := func() bool {
+		command, err := Terminal.AskString("")
+
+		if err != nil {
+			Terminal.PrintError(err)
+			return true
+		}
+
+		ExecuteCommand(command)
+		return true
+	}
+
+	/* Show payments of selected month in pop up */
+	showMonth := func() bool {
+		err := DrawMonthPayments(GraphBonds(), year, month, main.Rect())
+
+		if err != nil {
+			Terminal.PrintError(err)
+		}
+
+		return true
+	}
+
+	main.SetTitle(MainTitle()).RegisterAction(ActionNext, func() bool {
 		year++
 		return true
-	}).RegisterInput(DecreaseYearKey, func() bool {
+	}).RegisterAction(ActionPrev, func() bool {
 		year--
 
 		if year < CurrentYear {
@@ -343,64 +932,140 @@ func main() {
 		}
 
 		return true
-	}).RegisterInput(ExitKey, func() bool {
-		tmp := Terminal.AskChar("Really exit?[y/n]")
+	}).RegisterAction(ActionExit, func() bool {
+		err := AutosaveState.Flush()
+
+		if err != nil {
+			Terminal.PrintError(err)
+		}
+
+		if !Portfolios.IsDirty() {
+			return false
+		}
+
+		tmp := Terminal.AskChar("Unsaved changes will be lost. Really exit?[y/n]")
 
 		if tmp == 'y' || tmp == 'Y' {
 			return false
 		}
 
 		return true
-	}).RegisterInput(StartOfCommandKey, func() bool {
-		command, err := Terminal.AskString("")
+	}).RegisterAction(ActionCommand, askCommand).RegisterAction(ActionHelp, func() bool {
+		err := PopUpText("|Keys|", main.Rect(), ActiveKeymap.HelpLines()...)
 
 		if err != nil {
-			Terminal.Print(err.Error())
-			return true
+			Terminal.PrintError(err)
 		}
 
-		ExecuteCommand(command)
 		return true
-	}).RegisterInput(HelpKey, func() bool {
-		arr := []string{
-			fmt.Sprintf("  Keys for graph window"),
-			fmt.Sprintf("%c - Exit from programm, or close sub-window", ExitKey),
-			fmt.Sprintf("%c - Show next year info", IncreaseYearKey),
-			fmt.Sprintf("%c - Show previous year info", DecreaseYearKey),
-			fmt.Sprintf("%c - Start write command to terminal", StartOfCommandKey),
-			fmt.Sprintf("%c - Show this window", HelpKey),
+	}).RegisterAction(ActionAllPortfolios, func() bool {
+		ShowAllPortfolios = !ShowAllPortfolios
+		return true
+	}).RegisterAction(ActionRight, func() bool {
+		month++
+
+		if month > 12 {
+			month = 1
+			year++
 		}
 
-		err := PopUpScrollableList(arr, "|Info|", main.SizeY, main.PosY, main.posX)
+		return true
+	}).RegisterAction(ActionLeft, func() bool {
+		month--
 
-		if err != nil {
-			Terminal.Print(err.Error())
+		if month < 1 && year > CurrentYear {
+			month = 12
+			year--
 		}
 
+		month = max(month, 1)
+		return true
+	}).RegisterAction(ActionSelect, showMonth).RegisterAction(ActionGraphMode, func() bool {
+		graphMode = GraphModes[(slices.Index(GraphModes, graphMode)+1)%len(GraphModes)]
+		return true
+	}).RegisterAction(ActionLayout, func() bool {
+		Layouts.Next()
+		return true
+	}).RegisterAction(ActionUndo, func() bool {
+		ExecuteCommand("undo")
+		return true
+	}).RegisterAction(ActionRedo, func() bool {
+		ExecuteCommand("redo")
 		return true
 	})
 
-	yearInfo := YearInfo{CurrentYear, 0}
+	yearInfo := YearInfo{Year: CurrentYear}
+	graphOffsetX := func() int {
+		return (main.SizeX - 6) / 12
+	}
+
 	main.SetCustomDraw(func() {
-		yearInfo = DrawGraphByYear(AllBonds, year, main.Window, MaxX, MaxY-2, graphOffsetX)
+		yearInfo = DrawGraphByYear(GraphBonds(), year, month, graphMode, main.Window, main.SizeX, main.SizeY-1, graphOffsetX())
+	}).RegisterMouse(func(y, x int, button goncurses.MouseButton) bool {
+		_, clicks := WidgetMouse(button)
+		clicked := GraphMonthAt(graphMode, x, graphOffsetX())
+
+		if clicks == 0 || clicked == 0 {
+			return true
+		}
+
+		// Click selects month, click on selected month or double click shows its payments
+		open := widget.IsOpenClick(clicks, clicked == month)
+		month = clicked
+
+		if open {
+			return showMonth()
+		}
+
+		return true
 	})
 
-	infoHeight, infoWidth := MaxY/2, (MaxX/3)*1
-	infoPosY, infoPosX := 0, (MaxX/3)*2
-	info, err := goncurses.NewWindow(infoHeight, infoWidth, infoPosY, infoPosX)
+	calendar, err := CalendarViewNew(mainRect.SizeY, mainRect.SizeX, mainRect.PosY, mainRect.PosX)
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer calendar.Window.FreeWindow()
+	main.RegisterNextWindowAction(ActionCalendar, calendar.Window)
+	calendar.Window.RegisterNextWindowAction(ActionCalendar, main).RegisterNextWindowAction(ActionExit, main)
+
+	timeline, err := TimelineViewNew(mainRect.SizeY, mainRect.SizeX, mainRect.PosY, mainRect.PosX)
 
 	if err != nil {
 		panic(err)
 	}
 
+	defer timeline.Window.FreeWindow()
+	main.RegisterNextWindowAction(ActionTimeline, timeline.Window)
+	timeline.Window.RegisterNextWindowAction(ActionTimeline, main).RegisterNextWindowAction(ActionExit, main)
+
+	infoRect, _ := Layouts.Rect(PaneInfo)
+	info, err := FSMWindowNew(infoRect.SizeY, infoRect.SizeX, infoRect.PosY, infoRect.PosX)
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer info.FreeWindow()
+	info.SetTitle("|Info|").SetCustomDraw(func() {
+		DrawInfoByYear(info.Window, info.SizeX, info.SizeY, yearInfo)
+	})
+
+	terminalRect, _ := Layouts.Rect(PaneTerminal)
 	err = Terminal.Init(terminal.TerminalSettings{
-		Title:         "|Terminal|",
-		SizeY:         infoHeight - 1,
-		SizeX:         infoWidth,
-		PosX:          infoPosX,
-		PosY:          infoHeight,
-		DefaultEcho:   false,
-		DefaultCursor: 0,
+		Title:          "|Terminal|",
+		SizeY:          terminalRect.SizeY,
+		SizeX:          terminalRect.SizeX,
+		PosX:           terminalRect.PosX,
+		PosY:           terminalRect.PosY,
+		DefaultEcho:    false,
+		DefaultCursor:  0,
+		BorderAttr:     ActiveTheme.Border.Attr(),
+		TitleAttr:      ActiveTheme.Title.Attr(),
+		ErrorAttr:      ActiveTheme.Error.Attr(),
+		PromptAttr:     ActiveTheme.Prompt.Attr(),
+		ScrollbackSize: settings.Scrollback,
 	})
 
 	if err != nil {
@@ -408,34 +1073,171 @@ func main() {
 	}
 
 	defer Terminal.Delete()
+	terminalPane := FSMWindowWrap(Terminal.Window).SetTitle(Terminal.Settings.Title)
+	terminalPane.Window.Keypad(true)
+	scrollTerminal := func(lines func() int) SpecialInputFunc {
+		return func() bool {
+			Terminal.ScrollBack(lines())
+			return true
+		}
+	}
+
+	terminalPane.RegisterAction(ActionCommand, askCommand).RegisterAction(ActionSelect, askCommand).
+		RegisterAction(ActionUp, scrollTerminal(func() int { return 1 })).
+		RegisterAction(ActionDown, scrollTerminal(func() int { return -1 })).
+		RegisterAction(ActionPrev, scrollTerminal(Terminal.PageSize)).
+		RegisterAction(ActionNext, scrollTerminal(func() int { return -Terminal.PageSize() })).
+		RegisterMouse(func(y, x int, button goncurses.MouseButton) bool {
+			Terminal.ScrollBack(-WheelStep(button))
+			return true
+		})
+
+	for _, pane := range []*FSMWindow{info, terminalPane} {
+		pane.RegisterAction(ActionExit, func() bool {
+			Navigator.FocusPane(0)
+			return true
+		})
+	}
+
+	for _, window := range []*FSMWindow{main, calendar.Window, timeline.Window, info, terminalPane} {
+		window.RegisterAction(ActionFocus, func() bool {
+			Navigator.FocusNext()
+			return true
+		})
+	}
+
+	Navigator.AddPane(main).AddPane(info).AddPane(terminalPane)
+	Layouts.Attach(PaneMain, func(rect Rect, visible bool) {
+		for _, window := range []*FSMWindow{main, calendar.Window, timeline.Window} {
+			window.Resize(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+		}
+	}).Attach(PaneInfo, func(rect Rect, visible bool) {
+		info.Hidden = !visible
+
+		if visible {
+			info.Resize(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+		}
+	}).Attach(PaneTerminal, func(rect Rect, visible bool) {
+		Terminal.Hidden = !visible
+		terminalPane.Hidden = !visible
+
+		if !visible {
+			// Hidden terminal is shown over bottom of screen while asking for input
+			rect = Rect{HiddenTerminalHeight, MaxX, MaxY - 1 - HiddenTerminalHeight, 0}
+		}
+
+		terminalPane.Resize(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+		Terminal.Resize(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+	})
+
 	Terminal.Print("Inited successfully. Type ':help' for info")
+
+	if colorsErr != nil {
+		Terminal.PrintError(colorsErr)
+	}
+
+	if themeErr != nil {
+		Terminal.PrintError(themeErr)
+	}
+
+	if keymapErr != nil {
+		Terminal.PrintError(keymapErr)
+	}
+
+	if settingsErr != nil {
+		Terminal.PrintError(settingsErr)
+	}
+
+	dataDir, err := DataDir()
+
+	if err != nil {
+		Terminal.PrintError(fmt.Errorf("Autosave disabled: %s", err.Error()))
+	} else {
+		AutosaveState.Enabled = true
+		Terminal.Print("Data directory: " + dataDir)
+		loaded, err := LoadFromDataDir()
+
+		if err != nil {
+			Terminal.PrintError(err)
+		}
+
+		for _, item := range loaded {
+			if item.Err != nil {
+				Terminal.PrintError(fmt.Errorf("%s, file kept unchanged", item.Err.Error()))
+				continue
+			}
+
+			PrintBrokenEntries(item.Broken, true)
+			Terminal.Print(fmt.Sprintf("Loaded portfolio '%s': %d bonds", item.Name, len(Portfolios.Items[item.Name].Bonds)))
+		}
+
+		if len(loaded) != 0 {
+			Terminal.Print(fmt.Sprintf("Active portfolio: '%s'", Portfolios.Active))
+		}
+	}
+
 	var loop bool = true
+	resizeWatcher := ResizeWatcherNew()
 
 	for loop {
-		info.Box(0, 0)
+		err = AutosaveState.SaveIfDue()
+
+		if err != nil {
+			Terminal.PrintError(fmt.Errorf("Autosave failed: %s", err.Error()))
+		}
 
-		focus.Draw() // this must be before DrawInfoByYear
-		DrawInfoByYear(info, infoWidth, infoHeight, yearInfo)
+		// Resize can happen while pop up window is opened, so size is checked on each iteration, not only on KEY_RESIZE
+		resizeWatcher.Check()
+		MaxY, MaxX = stdscr.MaxYX()
+
+		if !Layouts.Fits(MaxY-1, MaxX) {
+			minY, minX := Layouts.MinSize()
+			DrawScreenTooSmall(stdscr, MaxY, MaxX, minY+1, minX)
+			Layouts.Invalidate() // Message erased windows, so they must be placed again
+			stdscr.Timeout(resizeWatcher.Timeout(AutosaveState.Timeout()))
+			stdscr.GetChar()
+			continue
+		}
 
-		stdscr.MovePrintf(MaxY-1, 0, "Help:%c ", HelpKey)
-		stdscr.Printf("Exit:%c ", ExitKey)
-		stdscr.Printf("Prev year:%c ", DecreaseYearKey)
-		stdscr.Printf("Next year:%c ", IncreaseYearKey)
+		if Layouts.Apply(MaxY-1, MaxX) {
+			stdscr.Clear()
+		}
+
+		main.SetTitle(MainTitle())
+		stdscr.MovePrint(MaxY-1, 0, ActiveKeymap.StatusLine()+" ")
+		stdscr.ClearToEOL()
+
+		if Portfolios.IsDirty() {
+			stdscr.Printf(" *Unsaved changes")
+		}
 
-		stdscr.Refresh()
-		Terminal.Refresh()
-		focus.DrawBox()
-		info.Refresh()
+		stdscr.NoutRefresh()
+		Navigator.Draw() // main pane is drawn before info, because it calculates yearInfo
+		goncurses.Update()
 
-		focus, loop = focus.Input()
+		Navigator.Timeout(resizeWatcher.Timeout(AutosaveState.Timeout()))
+		loop = Navigator.Input()
 	}
 }
 
 func init() {
-	RegisterCommand("help", Command{"':help <command>' - Show info about commands", CommandHelp})
-	RegisterCommand("list", Command{"':list' - Show list of all bonds", CommandList})
-	RegisterCommand("save", Command{"':save <file>' - Save bonds info into file", CommandSave})
-	RegisterCommand("load", Command{"':load <file>' - Load bonds info from file", CommandLoad})
-	RegisterCommand("new", Command{"':new' - Create new bonds and append it into list", CommandNewBonds})
-	RegisterCommand("delete", Command{"':delete <index>' - Delete bonds info from list", CommandDelete})
+	RegisterCommand("help", Command{"':help <command>' - Show info about commands", CommandHelp, false})
+	RegisterCommand("list", Command{"':list [filter]' - Show list of bonds matched by filter or active filter", CommandList, false})
+	RegisterCommand("save", Command{"':save <file> [filter]' - Save bonds info into file, only matched by filter if given", CommandSave, false})
+	RegisterCommand("load", Command{"':load <file>' - Load bonds info from file", CommandLoad, true})
+	RegisterCommand("new", Command{"':new' - Open form for create new bonds and append it into list", CommandNewBonds, false})
+	RegisterCommand("delete", Command{"':delete <bond>' - Delete bonds info from list, bond is id, isin, name prefix or #index, bare number is not index", CommandDelete, true})
+	RegisterCommand("diff", Command{"':diff <file>' - Show difference between bonds and file", CommandDiff, false})
+	RegisterCommand("portfolio", Command{"':portfolio <list|new|switch|rename|close|all> [name]' - Manage named portfolios", CommandPortfolio, true})
+	RegisterCommand("copy", Command{"':copy <bond>' - Append copy of bond with new id", CommandCopy, true})
+	RegisterCommand("show", Command{"':show <bond>' - Show all fields and payment schedule of bond", CommandShow, false})
+	RegisterCommand("dates", Command{"':dates <bond>' - Same as show", CommandShow, false})
+	RegisterCommand("edit", Command{"':edit <bond> [field] [value]' - Edit bonds fields, current values prefilled", CommandEdit, true})
+	RegisterCommand("filter", Command{"':filter [expression]' - Set filter for graph and list like 'tag:bank currency:RUB', clear if empty", CommandFilter, false})
+	RegisterCommand("undo", Command{"':undo' - Revert last change of active portfolio", CommandUndo, true})
+	RegisterCommand("redo", Command{"':redo' - Apply last undone change again", CommandRedo, true})
+	RegisterCommand("history", Command{"':history' - Show changes of active portfolio", CommandHistory, false})
+	RegisterCommand("layout", Command{"':layout [name]' - Switch layout of windows, show layouts if name not given", CommandLayout, false})
+	RegisterCommand("log", Command{"':log <file>' - Save all lines of terminal scrollback into file", CommandLog, false})
+	RegisterCommand("merge", Command{"':merge <file>' - Merge bonds from file, ask for resolve conflicts", CommandMerge, true})
 }
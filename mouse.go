@@ -0,0 +1,49 @@
+/*
+Mouse events of UI
+Navigator receives KEY_MOUSE, clicked pane gets focus, wheel scrolls pane under pointer without focusing it,
+other events passed to top window with coordinates relative to it
+Pop up windows are modal, events outside of them are ignored
+Mouse capture disables text selection of terminal, most terminals still select text with shift pressed
+*/
+package main
+
+import (
+	"github.com/gbin/goncurses"
+)
+
+const (
+	MouseClick       = goncurses.MouseButton(goncurses.M_B1_CLICKED)
+	MouseDoubleClick = goncurses.MouseButton(goncurses.M_B1_DBL_CLICKED)
+	MouseWheelUp     = goncurses.MouseButton(goncurses.M_B4_PRESSED)
+	MouseWheelDown   = MouseWheelUp << 5 // BUTTON5_PRESSED of ncurses 6, not defined by goncurses
+
+	MouseWheelLines = 3 // Lines scrolled by one step of wheel
+)
+
+/* Custom func for process mouse event, y and x are relative to window, returning false mean exit */
+type MouseInputFunc func(y, x int, button goncurses.MouseButton) bool
+
+/* Enable reporting of clicks and wheel, return false if terminal has no mouse */
+func MouseInit() bool {
+	mask := MouseClick | MouseDoubleClick | MouseWheelUp | MouseWheelDown
+	return goncurses.MouseMask(mask, nil) != 0
+}
+
+/* Return scroll step of wheel event: negative for up, positive for down, zero for other buttons */
+func WheelStep(button goncurses.MouseButton) int {
+	switch {
+	case button&MouseWheelUp != 0:
+		return -MouseWheelLines
+
+	case button&MouseWheelDown != 0:
+		return MouseWheelLines
+
+	default:
+		return 0
+	}
+}
+
+/* Check is event a click by left button */
+func IsClick(button goncurses.MouseButton) bool {
+	return button&(MouseClick|MouseDoubleClick) != 0
+}
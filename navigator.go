@@ -0,0 +1,187 @@
+/*
+Navigator of FSMWindows
+Panes are windows placed by layout (main, info, terminal), one of them is focused, ActionFocus cycles focus between visible panes
+Pop up windows are pushed on stack over panes, top of stack gets input, pop returns to window under it
+Main loop calls Draw and Input of navigator instead of single window
+Mouse events are routed by navigator, see mouse.go
+*/
+package main
+
+import (
+	"slices"
+
+	"github.com/gbin/goncurses"
+)
+
+type WindowNavigator struct {
+	panes   []*FSMWindow // Panes in order of cycling focus, first is main pane
+	focused int          // Index of focused pane
+	stack   []*FSMWindow // Pop up windows, last is on top
+}
+
+var (
+	Navigator = WindowNavigatorNew()
+)
+
+func WindowNavigatorNew() *WindowNavigator {
+	obj := new(WindowNavigator)
+	obj.panes = make([]*FSMWindow, 0)
+	obj.stack = make([]*FSMWindow, 0)
+
+	return obj
+}
+
+/* Append pane, first added pane is focused */
+func (self *WindowNavigator) AddPane(window *FSMWindow) *WindowNavigator {
+	window.Window.Keypad(true)
+	self.panes = append(self.panes, window)
+	return self
+}
+
+/* Return window which gets input: top of stack or focused pane */
+func (self *WindowNavigator) Top() *FSMWindow {
+	if len(self.stack) != 0 {
+		return self.stack[len(self.stack)-1]
+	}
+
+	if self.panes[self.focused].Hidden {
+		self.focused = 0
+	}
+
+	return self.panes[self.focused]
+}
+
+/* Show pop up window over all windows */
+func (self *WindowNavigator) Push(window *FSMWindow) {
+	window.Window.Keypad(true)
+	self.stack = append(self.stack, window)
+}
+
+/* Remove top pop up window and free it, panes are never removed */
+func (self *WindowNavigator) Pop() {
+	if len(self.stack) == 0 {
+		return
+	}
+
+	self.Close(self.stack[len(self.stack)-1])
+}
+
+/* Remove pop up window from any place of stack and free it, window may open another pop up before closing */
+func (self *WindowNavigator) Close(window *FSMWindow) {
+	ind := slices.Index(self.stack, window)
+
+	if ind < 0 {
+		return
+	}
+
+	self.stack = slices.Delete(self.stack, ind, ind+1)
+	window.FreeWindow()
+	self.touchAll()
+}
+
+/* Move focus to next visible pane, pop up windows keep focus while opened */
+func (self *WindowNavigator) FocusNext() {
+	for step := 1; step <= len(self.panes); step++ {
+		ind := (self.focused + step) % len(self.panes)
+
+		if !self.panes[ind].Hidden {
+			self.focused = ind
+			return
+		}
+	}
+}
+
+/* Move focus to pane with given index */
+func (self *WindowNavigator) FocusPane(ind int) {
+	self.focused = ind
+}
+
+/* Replace window which switched to next one by RegisterNextWindow */
+func (self *WindowNavigator) replace(current, next *FSMWindow) {
+	if len(self.stack) != 0 && self.stack[len(self.stack)-1] == current {
+		self.stack[len(self.stack)-1] = next
+		return
+	}
+
+	if self.panes[self.focused] == current {
+		self.panes[self.focused] = next
+	}
+}
+
+/* Mark all windows as changed, so they will be fully redrawn over closed pop up */
+func (self *WindowNavigator) touchAll() {
+	for _, window := range slices.Concat(self.panes, self.stack) {
+		window.Window.Touch()
+	}
+}
+
+/*
+Draw visible panes, then pop up windows from bottom to top
+Windows are refreshed virtually, call goncurses.Update after it
+*/
+func (self *WindowNavigator) Draw() {
+	top := self.Top()
+
+	for _, window := range slices.Concat(self.panes, self.stack) {
+		if window.Hidden {
+			continue
+		}
+
+		window.Focused = window == top
+		window.Draw()
+		window.DrawBox()
+	}
+}
+
+/* Pass input into top window, return false if window wants exit */
+func (self *WindowNavigator) Input() bool {
+	top := self.Top()
+	key := top.Window.GetChar()
+
+	if key == goncurses.KEY_MOUSE {
+		return self.inputMouse()
+	}
+
+	next, status := top.InputKey(key)
+
+	if next != top {
+		self.replace(top, next)
+	}
+
+	return status
+}
+
+/*
+If no pop up opened, click focuses pane under it and wheel scrolls pane under it without focusing
+Other events and all events while pop up opened go to top window
+*/
+func (self *WindowNavigator) inputMouse() bool {
+	event := goncurses.GetMouse()
+
+	if event == nil {
+		return true
+	}
+
+	if len(self.stack) != 0 {
+		return self.Top().InputMouse(event)
+	}
+
+	for ind, pane := range self.panes {
+		if pane.Hidden || !pane.Encloses(event.Y, event.X) {
+			continue
+		}
+
+		if IsClick(event.State) {
+			self.focused = ind
+		} else if WheelStep(event.State) != 0 {
+			return pane.InputMouse(event)
+		}
+	}
+
+	return self.Top().InputMouse(event)
+}
+
+/* Set input timeout of top window in milliseconds */
+func (self *WindowNavigator) Timeout(delay int) {
+	self.Top().Window.Timeout(delay)
+}
@@ -0,0 +1,124 @@
+/*
+Pop up windows with widgets, pushed to Navigator
+Pop up is centered relative to parent window, keys of active keymap are translated into widget commands
+Widget closes own pop up by returning widget.ResultClose
+*/
+package main
+
+import (
+	"bonds_payment_calendar/terminal"
+	"bonds_payment_calendar/widget"
+
+	"github.com/gbin/goncurses"
+)
+
+var (
+	widgetCommands = []struct {
+		Action  string
+		Command widget.Command
+	}{
+		{ActionUp, widget.CommandUp},
+		{ActionDown, widget.CommandDown},
+		{ActionLeft, widget.CommandLeft},
+		{ActionRight, widget.CommandRight},
+		{ActionPrev, widget.CommandPageUp},
+		{ActionNext, widget.CommandPageDown},
+		{ActionSelect, widget.CommandSelect},
+		{ActionExit, widget.CommandCancel},
+		{ActionFocus, widget.CommandNext},
+	}
+)
+
+/* Area of screen above status line, parent of pop ups opened by commands */
+func ScreenRect() Rect {
+	return Rect{MaxY - 1, MaxX, 0, 0}
+}
+
+/*
+Translate key into widget command by active keymap, 'esc' always cancels
+Printable keys are passed as text into widgets which edit text
+*/
+func WidgetCommand(key goncurses.Key, content widget.Widget) widget.Command {
+	if key == terminal.KeyEscape {
+		return widget.CommandCancel
+	}
+
+	if input, ok := content.(widget.TextInput); ok && input.EditsText() && (key >= ' ' && key < 127 || widget.IsTextByte(key)) {
+		return widget.CommandNone
+	}
+
+	for _, item := range widgetCommands {
+		if ActiveKeymap.Is(item.Action, key) {
+			return item.Command
+		}
+	}
+
+	return widget.CommandNone
+}
+
+/* Translate mouse button into lines of wheel and count of clicks */
+func WidgetMouse(button goncurses.MouseButton) (int, int) {
+	switch {
+	case button&MouseDoubleClick != 0:
+		return 0, 2
+
+	case button&MouseClick != 0:
+		return 0, 1
+
+	default:
+		return WheelStep(button), 0
+	}
+}
+
+/* Open boxed pop up with widget, size is preferred size of widget limited by parent */
+func PopUpWidget(content widget.Widget, title string, parent Rect) (*FSMWindow, error) {
+	sizeY, sizeX := content.Size(parent.SizeY-2, parent.SizeX-2)
+	sizeX = max(sizeX, len(title))
+	rect := widget.Center(widget.Rect(parent), sizeY+2, sizeX+2)
+	window, err := FSMWindowNew(rect.SizeY, rect.SizeX, rect.PosY, rect.PosX)
+
+	if err != nil {
+		return nil, err
+	}
+
+	closeIf := func(result widget.Result) bool {
+		if result == widget.ResultClose {
+			Navigator.Close(window)
+		}
+
+		return true
+	}
+
+	window.SetTitle(title).SetCustomDraw(func() {
+		content.Draw(window.Window, widget.Rect{SizeY: window.SizeY - 2, SizeX: window.SizeX - 2, PosY: 1, PosX: 1})
+	}).RegisterDefaultInput(func(key goncurses.Key) bool {
+		return closeIf(content.Input(WidgetCommand(key, content), key))
+	}).RegisterMouse(func(y, x int, button goncurses.MouseButton) bool {
+		wheel, clicks := WidgetMouse(button)
+		return closeIf(content.Mouse(y-1, x-1, wheel, clicks))
+	})
+
+	Navigator.Push(window)
+	return window, nil
+}
+
+/* Show lines as scrollable text with word wrap */
+func PopUpText(title string, parent Rect, lines ...string) error {
+	_, err := PopUpWidget(widget.TextViewNew(lines...), title, parent)
+	return err
+}
+
+/*
+Ask yes/no question, answer passed into function after pop up closed,
+so function can open own pop ups without confirm staying under them
+*/
+func PopUpConfirm(question string, parent Rect, onAnswer func(yes bool)) error {
+	var window *FSMWindow
+	confirm := widget.ConfirmNew(question, func(yes bool) {
+		Navigator.Close(window) // Pop up closes itself again after answer, closing twice does nothing
+		onAnswer(yes)
+	})
+
+	window, err := PopUpWidget(confirm, "|Confirm|", parent)
+	return err
+}
@@ -0,0 +1,303 @@
+/*
+File for default data directory and autosave
+Data directory defined by XDG base directory specification:
+    $XDG_DATA_HOME/bonds_calendar or $HOME/.local/share/bonds_calendar
+Each portfolio saved as '<name>.json', name of last used portfolio saved in 'last_portfolio'
+All saved portfolios are opened at startup, portfolio which failed to load is never overwritten
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DataDirName         = "bonds_calendar"
+	LastPortfolioFile   = "last_portfolio"
+	PortfolioFileSuffix = ".json"
+	DefaultAutosaveWait = 2 * time.Second
+)
+
+/* Debounced saving of all portfolios into data directory */
+type Autosave struct {
+	Wait     time.Duration // Time from last change to saving
+	Enabled  bool          // False if data directory is not available
+	pending  bool
+	deadline time.Time
+}
+
+var (
+	AutosaveState = Autosave{Wait: DefaultAutosaveWait}
+)
+
+/* Return path to data directory, create it if not exist */
+func DataDir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+
+	if base == "" {
+		home, err := os.UserHomeDir()
+
+		if err != nil {
+			return "", err
+		}
+
+		base = filepath.Join(home, ".local", "share")
+	}
+
+	dir := filepath.Join(base, DataDirName)
+	err := os.MkdirAll(dir, 0755)
+
+	if err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+/* Return path to file of portfolio with given name in data directory */
+func PortfolioPath(dir, name string) string {
+	return filepath.Join(dir, name+PortfolioFileSuffix)
+}
+
+/* Result of loading one portfolio from data directory */
+type LoadedPortfolio struct {
+	Name   string
+	Broken []bonds.BrokenEntry // Invalid entries kept in portfolio
+	Err    error               // Portfolio not opened if loading failed
+}
+
+/* Names of portfolios saved in data directory, sorted by name */
+func SavedPortfolioNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), PortfolioFileSuffix)
+
+		if found && !entry.IsDir() && bonds.CheckPortfolioName(name) == nil {
+			result = append(result, name)
+		}
+	}
+
+	return result, nil
+}
+
+/* Check is portfolio with given name saved in data directory, so new portfolio with this name would overwrite it */
+func IsPortfolioSaved(name string) bool {
+	dir, err := DataDir()
+
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(PortfolioPath(dir, name))
+	return err == nil
+}
+
+/* Load portfolio from data directory into new bonds container without undo history */
+func LoadPortfolioFile(dir, name string) (*bonds.Bonds, []bonds.BrokenEntry, error) {
+	obj := bonds.BondsNew()
+	broken, err := obj.LoadFromFile(PortfolioPath(dir, name))
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Can't load portfolio '%s': %s", name, err.Error())
+	}
+
+	obj.History.Clear()
+	return obj, broken, nil
+}
+
+/* Open saved portfolio, which is not opened yet, as new portfolio */
+func OpenSavedPortfolio(name string) ([]bonds.BrokenEntry, error) {
+	dir, err := DataDir()
+
+	if err != nil {
+		return nil, err
+	}
+
+	obj, broken, err := LoadPortfolioFile(dir, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = Portfolios.Create(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	Portfolios.Items[name] = obj
+	return broken, nil
+}
+
+/* Return name based on given one, which is not used by opened or saved portfolio */
+func FreePortfolioName(base string) string {
+	for ind := 2; ; ind++ {
+		name := fmt.Sprintf("%s-%d", base, ind)
+		_, exist := Portfolios.Items[name]
+
+		if !exist && !IsPortfolioSaved(name) {
+			return name
+		}
+	}
+}
+
+/* Move file of renamed portfolio in data directory, nothing to do if portfolio was not saved yet */
+func RenamePortfolioFile(oldName, newName string) error {
+	dir, err := DataDir()
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(PortfolioPath(dir, oldName), PortfolioPath(dir, newName))
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+/* Save all portfolios into data directory and remember active one as last used */
+func SaveToDataDir() error {
+	dir, err := DataDir()
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range Portfolios.Names {
+		err = Portfolios.Items[name].SaveToFile(PortfolioPath(dir, name))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, LastPortfolioFile), []byte(Portfolios.Active), 0666)
+}
+
+/*
+Open all portfolios saved in data directory, last used one became active
+Portfolios which failed to load are not opened and their files are kept untouched,
+if there is no opened portfolio left, empty one is created with name not used by any file
+Invalid bonds are skipped, kept in portfolio for saving back and returned as broken entries
+*/
+func LoadFromDataDir() ([]LoadedPortfolio, error) {
+	dir, err := DataDir()
+
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := SavedPortfolioNames(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]LoadedPortfolio, 0, len(names))
+	var loaded *bonds.Portfolios
+
+	for _, name := range names {
+		obj, broken, err := LoadPortfolioFile(dir, name)
+		result = append(result, LoadedPortfolio{name, broken, err})
+
+		if err != nil {
+			continue
+		}
+
+		if loaded == nil {
+			loaded = bonds.PortfoliosNew(name)
+		} else {
+			loaded.Create(name) // Names of files are unique and checked already
+		}
+
+		loaded.Items[name] = obj
+	}
+
+	if loaded == nil {
+		// Nothing opened, keep empty portfolio, but don't let it overwrite file which failed to load
+		if IsPortfolioSaved(Portfolios.Active) {
+			err = Portfolios.Rename(Portfolios.Active, FreePortfolioName(Portfolios.Active))
+		}
+
+		AllBonds = Portfolios.Current()
+		return result, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, LastPortfolioFile))
+
+	if err == nil {
+		loaded.Switch(strings.TrimSpace(string(content))) // Last portfolio may be failed or removed, first one stays active then
+	}
+
+	Portfolios = loaded
+	AllBonds = Portfolios.Current()
+	return result, nil
+}
+
+/* Mark that bonds changed, saving will be done after Wait without new changes */
+func (self *Autosave) Touch() {
+	if !self.Enabled {
+		return
+	}
+
+	self.pending = true
+	self.deadline = time.Now().Add(self.Wait)
+}
+
+/* Check is there any changes not saved yet */
+func (self *Autosave) Pending() bool {
+	return self.pending
+}
+
+/*
+Return timeout in milliseconds for ncurses input until saving must be done
+Return -1 (blocking input) if nothing to save
+*/
+func (self *Autosave) Timeout() int {
+	if !self.pending {
+		return -1
+	}
+
+	return max(0, int(time.Until(self.deadline).Milliseconds()))
+}
+
+/* Save if wait time passed after last change */
+func (self *Autosave) SaveIfDue() error {
+	if !self.pending || time.Now().Before(self.deadline) {
+		return nil
+	}
+
+	return self.Flush()
+}
+
+/* Save immediately if there are any changes, if saving failed it will be retried after Wait */
+func (self *Autosave) Flush() error {
+	if !self.pending {
+		return nil
+	}
+
+	err := SaveToDataDir()
+
+	if err != nil {
+		self.deadline = time.Now().Add(self.Wait)
+		return err
+	}
+
+	self.pending = false
+	return nil
+}
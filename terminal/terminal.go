@@ -4,22 +4,36 @@ Always boxes
 Features:
 	print strings in terminal window
 	get inputs from user with converting
+	keep printed lines in scrollback buffer, page through it and restore it after resize
 */
 package terminal
 
 import (
+	"bonds_payment_calendar/widget"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/gbin/goncurses"
 )
 
+const (
+	KeyEscape = 27 // Key code of 'esc', cancel input with prefilled value
+
+	DefaultScrollbackSize = 1000 // Count of printed messages kept in scrollback buffer
+)
+
 type TerminalSettings struct {
-	SizeX, SizeY  int
-	PosX, PosY    int
-	DefaultEcho   bool   // Default value for your programm
-	DefaultCursor byte   // Default value for your programm
-	Title         string // Title for terminal
+	SizeX, SizeY   int
+	PosX, PosY     int
+	DefaultEcho    bool           // Default value for your programm
+	DefaultCursor  byte           // Default value for your programm
+	Title          string         // Title for terminal
+	BorderAttr     goncurses.Char // Attribute of box, color pair for example
+	TitleAttr      goncurses.Char
+	ErrorAttr      goncurses.Char // Attribute of lines printed by PrintError
+	PromptAttr     goncurses.Char // Attribute of questions and input line
+	ScrollbackSize int            // Count of printed messages kept for scrolling back, DefaultScrollbackSize if zero
 
 	// clearPosX, clearPosY int // position for set cursor in and delete line
 	printPosX, printPosY int // position for set cursor in and print line
@@ -30,6 +44,17 @@ type TerminalSettings struct {
 type Terminal struct {
 	Window   *goncurses.Window
 	Settings TerminalSettings
+	Hidden   bool // Printed content is not shown, terminal is shown only while asking for input
+
+	asking     bool
+	scrollback []printedLine // Printed messages, oldest first
+	offset     int           // Count of wrapped lines scrolled back from the last one, zero shows last lines
+}
+
+/* Message kept in scrollback buffer, wrapped by width of terminal while drawing */
+type printedLine struct {
+	Text string
+	Attr goncurses.Char
 }
 
 // TODO:
@@ -52,21 +77,37 @@ func (self *Terminal) Init(settings TerminalSettings) error {
 		return err
 	}
 
-	// settings.clearPosX = 1
-	// settings.clearPosY = 1
-	settings.printPosX = 1
-	settings.printPosY = settings.SizeY - 3
-	settings.inputPosX = 1
-	settings.inputPosY = settings.SizeY - 2
-	settings.maxInput = settings.SizeX - 4
-
 	self.Settings = settings
+	self.calcPositions()
 	self.Window.ScrollOk(true)
 	self.Refresh()
 
 	return err
 }
 
+/* Change size and position of terminal, printed content is restored from scrollback buffer */
+func (self *Terminal) Resize(sizeY, sizeX, posY, posX int) *Terminal {
+	self.Window.Resize(sizeY, sizeX)
+	self.Window.MoveWindow(posY, posX)
+	self.Settings.SizeY, self.Settings.SizeX = sizeY, sizeX
+	self.Settings.PosY, self.Settings.PosX = posY, posX
+	self.calcPositions()
+	self.ScrollBack(0)
+
+	return self
+}
+
+/* Help function - calculate positions of print and input lines by size */
+func (self *Terminal) calcPositions() {
+	// self.Settings.clearPosX = 1
+	// self.Settings.clearPosY = 1
+	self.Settings.printPosX = 1
+	self.Settings.printPosY = self.Settings.SizeY - 3
+	self.Settings.inputPosX = 1
+	self.Settings.inputPosY = self.Settings.SizeY - 2
+	self.Settings.maxInput = self.Settings.SizeX - 4
+}
+
 /* Call .Delete for window for free memory */
 func (self *Terminal) Delete() *Terminal {
 	self.Window.ScrollOk(false)
@@ -77,9 +118,18 @@ func (self *Terminal) Delete() *Terminal {
 
 /* Draw box, title and refresh changes */
 func (self *Terminal) Refresh() {
-	self.Window.Box(0, 0)
+	attr := self.Settings.BorderAttr
+	self.Window.Border(
+		goncurses.ACS_VLINE|attr, goncurses.ACS_VLINE|attr, goncurses.ACS_HLINE|attr, goncurses.ACS_HLINE|attr,
+		goncurses.ACS_ULCORNER|attr, goncurses.ACS_URCORNER|attr, goncurses.ACS_LLCORNER|attr, goncurses.ACS_LRCORNER|attr,
+	)
+	self.Window.AttrOn(self.Settings.TitleAttr)
 	self.Window.MovePrint(0, self.Settings.SizeX/2-5, self.Settings.Title)
-	self.Window.Refresh()
+	self.Window.AttrOff(self.Settings.TitleAttr)
+
+	if !self.Hidden || self.asking {
+		self.Window.Refresh()
+	}
 }
 
 /*
@@ -106,29 +156,145 @@ func (self *Terminal) Clear() error {
 Print given msg to terminal. Split msg if it can't fit in one terminal line (Terminal.SizeX)
 */
 func (self *Terminal) Print(msg string) *Terminal {
-	if len(msg) >= (self.Settings.SizeX - 2) {
-		// print splitted
-		var startInd int
-		var endInd int = self.Settings.SizeX - 2
+	return self.printAttr(msg, goncurses.A_NORMAL)
+}
 
-		for endInd < len(msg) {
-			self.printScrolled(msg[startInd:endInd])
+/* Same as Print, but message of error highlighted by ErrorAttr */
+func (self *Terminal) PrintError(err error) *Terminal {
+	return self.printAttr(err.Error(), self.Settings.ErrorAttr)
+}
 
-			startInd = endInd
-			endInd += self.Settings.SizeX - 2
-		}
+/*
+Help function - print msg splitted by terminal lines with given attribute
+If terminal scrolled back, it returns to last lines
+*/
+func (self *Terminal) printAttr(msg string, attr goncurses.Char) *Terminal {
+	if self.remember(msg, attr) {
+		return self
+	}
 
-		self.printScrolled(msg[startInd:])
+	self.Window.AttrOn(attr)
 
-	} else {
-		self.printScrolled(msg)
+	for _, line := range self.splitLine(msg) {
+		self.printScrolled(line)
 	}
 
+	self.Window.AttrOff(attr)
 	self.Refresh()
 
 	return self
 }
 
+/* Help function - split msg into lines which fit in terminal width */
+func (self *Terminal) splitLine(msg string) []string {
+	width := max(self.Settings.SizeX-2, 1)
+	result := make([]string, 0, len(msg)/width+1)
+
+	for len(msg) > width {
+		result = append(result, msg[:width])
+		msg = msg[width:]
+	}
+
+	return append(result, msg)
+}
+
+/*
+Help function - append msg into scrollback buffer, oldest messages are dropped if buffer is full
+Return true if terminal was scrolled back and redrawn with msg, so msg must not be printed again
+*/
+func (self *Terminal) remember(msg string, attr goncurses.Char) bool {
+	size := self.Settings.ScrollbackSize
+
+	if size <= 0 {
+		size = DefaultScrollbackSize
+	}
+
+	self.scrollback = append(self.scrollback, printedLine{msg, attr})
+
+	if len(self.scrollback) > size {
+		self.scrollback = self.scrollback[len(self.scrollback)-size:]
+	}
+
+	if self.offset == 0 {
+		return false
+	}
+
+	self.offset = 0
+	self.redraw()
+	return true
+}
+
+/* Count of lines for printed messages, page size for scrolling */
+func (self *Terminal) PageSize() int {
+	return max(self.Settings.printPosY, 1)
+}
+
+/* Return all messages from scrollback buffer, oldest first */
+func (self *Terminal) Scrollback() []string {
+	result := make([]string, 0, len(self.scrollback))
+
+	for _, line := range self.scrollback {
+		result = append(result, line.Text)
+	}
+
+	return result
+}
+
+/* Check is terminal scrolled back, so last printed lines are not shown */
+func (self *Terminal) IsScrolledBack() bool {
+	return self.offset != 0
+}
+
+/*
+Scroll to older lines by given count, negative count scrolls to newer lines
+Scrolling is limited by first line in buffer and last printed line
+*/
+func (self *Terminal) ScrollBack(lines int) *Terminal {
+	var total int
+
+	for _, line := range self.scrollback {
+		total += len(self.splitLine(line.Text))
+	}
+
+	self.offset = max(min(self.offset+lines, total-self.PageSize()), 0)
+	self.redraw()
+
+	return self
+}
+
+/* Help function - draw lines from scrollback buffer by offset, position in buffer shown on input line */
+func (self *Terminal) redraw() {
+	self.Window.Erase()
+	skip := self.offset
+	y := self.Settings.printPosY
+
+	for ind := len(self.scrollback) - 1; ind >= 0 && y > 0; ind-- {
+		line := self.scrollback[ind]
+		parts := self.splitLine(line.Text)
+		self.Window.AttrOn(line.Attr)
+
+		for part := len(parts) - 1; part >= 0 && y > 0; part-- {
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			self.Window.MovePrint(y, self.Settings.printPosX, parts[part])
+			y--
+		}
+
+		self.Window.AttrOff(line.Attr)
+	}
+
+	if self.offset != 0 {
+		self.Window.AttrOn(self.Settings.PromptAttr)
+		self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX, fmt.Sprintf("-- scrolled back by %d lines --", self.offset))
+		self.Window.AttrOff(self.Settings.PromptAttr)
+	}
+
+	self.Refresh()
+}
+
 /*
 Show message and ask for input string.
 Function return after a pressing 'enter'
@@ -145,6 +311,21 @@ func (self *Terminal) AskString(question string) (string, error) {
 	return result, err
 }
 
+/*
+Show message and ask for input string with prefilled value, which can be edited.
+Function return after a pressing 'enter', return error if input canceled with 'esc'
+*/
+func (self *Terminal) AskStringDefault(question, value string) (string, error) {
+	goncurses.Cursor(1)
+
+	result, err := self.askInputDefault(question, value)
+	self.Print(result)
+
+	goncurses.Echo(self.Settings.DefaultEcho)
+	goncurses.Cursor(self.Settings.DefaultCursor)
+	return result, err
+}
+
 /*
 Show message and ask for input char.
 Function return after a pressin any key
@@ -218,21 +399,86 @@ func (self *Terminal) printScrolled(oneLineMsg string) {
 	self.Window.ClearToEOL()
 }
 
+/*
+Help function - print question and input line prompt, both highlighted by PromptAttr
+Input waits without timeout, window may have timeout from main loop if it is focused
+*/
+func (self *Terminal) printPrompt(question string) {
+	self.Window.Timeout(-1)
+	scrolled := self.remember(question, self.Settings.PromptAttr)
+	self.Window.AttrOn(self.Settings.PromptAttr)
+
+	if !scrolled {
+		self.printScrolled(question)
+	}
+
+	self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX, "> ")
+	self.Window.AttrOff(self.Settings.PromptAttr)
+	self.Refresh()
+}
+
 /* Help function - Correctly process a scrolling, printing a request prompt, and get string from user */
 func (self *Terminal) askInput(question string) (string, error) {
-	self.printScrolled(question)
-	self.Refresh()
-	self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX, "> ")
+	self.asking = true
+	defer func() { self.asking = false }()
+	self.printPrompt(question)
+	self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX+2, "")
 	result, err := self.Window.GetString(self.Settings.maxInput)
 
 	return result, err
 }
 
+/*
+Help function - same as askInput, but input starts with given value
+Echo must be disabled, input printed by itself
+*/
+func (self *Terminal) askInputDefault(question, value string) (string, error) {
+	self.asking = true
+	defer func() { self.asking = false }()
+	self.printPrompt(question)
+	goncurses.Echo(false)
+	input := []rune(value)
+	var text widget.TextDecoder // Echo is disabled, so multi-byte chars are collected here
+
+	for {
+		if len(input) > self.Settings.maxInput {
+			input = input[:self.Settings.maxInput]
+		}
+
+		self.Window.AttrOn(self.Settings.PromptAttr)
+		self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX, "> ")
+		self.Window.AttrOff(self.Settings.PromptAttr)
+		self.Window.Print(string(input))
+		self.Window.ClearToEOL()
+		self.Refresh()
+		key := self.Window.GetChar()
+
+		switch key {
+		case goncurses.KEY_ENTER, goncurses.KEY_RETURN, '\r':
+			return string(input), nil
+
+		case KeyEscape:
+			return "", fmt.Errorf("Input canceled")
+
+		case goncurses.KEY_BACKSPACE, 127, 8:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+
+		default:
+			if char, isText := text.Decode(key); isText {
+				input = append(input, char)
+			}
+		}
+	}
+}
+
 /* Help function - Correctly process a scrolling, printing a request prompt, and get pressed key from user */
 func (self *Terminal) askChar(question string) goncurses.Key {
-	self.printScrolled(question)
-	self.Refresh()
-	self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX, "> ")
+	self.asking = true
+	defer func() { self.asking = false }()
+	self.printPrompt(question)
+	self.Window.MovePrint(self.Settings.inputPosY, self.Settings.inputPosX+2, "")
 	result := self.Window.GetChar()
 
 	return result
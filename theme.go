@@ -0,0 +1,222 @@
+/*
+Color theme of UI
+Each role of theme is drawn by own ncurses color pair
+If terminal has no colors, roles are drawn by monochrome attributes: reverse, bold, underline
+Theme loaded from 'theme.json' in config directory, roles missing in file are taken from DefaultTheme
+Example of theme.json:
+    {"border": {"fg": "blue"}, "error": {"fg": "red", "bold": true}, "series": [{"fg": "green"}, {"fg": "yellow"}]}
+Colors: default, black, red, green, yellow, blue, magenta, cyan, white
+*/
+package main
+
+import (
+	"bonds_payment_calendar/widget"
+	"fmt"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	ThemeFile = "theme.json"
+
+	defaultColor int16 = -1 // Color of terminal, needs use_default_colors
+)
+
+/* Colors of one role, attr is calculated by Theme.Init */
+type ThemeColor struct {
+	Foreground string `json:"fg"`
+	Background string `json:"bg"`
+	Bold       bool   `json:"bold"`
+
+	mono goncurses.Char // Attribute for terminals without colors
+	attr goncurses.Char
+}
+
+type Theme struct {
+	Border ThemeColor   `json:"border"`
+	Focus  ThemeColor   `json:"focus"` // Border of window which gets input
+	Title  ThemeColor   `json:"title"`
+	Cursor ThemeColor   `json:"cursor"` // Selected row, month or day
+	Match  ThemeColor   `json:"match"`  // Search matches, days with payments
+	Error  ThemeColor   `json:"error"`
+	Prompt ThemeColor   `json:"prompt"` // Questions and input line of terminal
+	Series []ThemeColor `json:"series"` // Stacked series of graphs
+
+	Colors bool // Terminal has colors, filled by Init
+}
+
+var (
+	themeColorNames = map[string]int16{
+		"":        defaultColor,
+		"default": defaultColor,
+		"black":   goncurses.C_BLACK,
+		"red":     goncurses.C_RED,
+		"green":   goncurses.C_GREEN,
+		"yellow":  goncurses.C_YELLOW,
+		"blue":    goncurses.C_BLUE,
+		"magenta": goncurses.C_MAGENTA,
+		"cyan":    goncurses.C_CYAN,
+		"white":   goncurses.C_WHITE,
+	}
+
+	ActiveTheme = DefaultTheme()
+)
+
+func DefaultTheme() *Theme {
+	return &Theme{
+		Border: ThemeColor{Foreground: "cyan"},
+		Focus:  ThemeColor{Foreground: "yellow", Bold: true, mono: goncurses.A_BOLD},
+		Title:  ThemeColor{Foreground: "yellow", Bold: true, mono: goncurses.A_BOLD},
+		Cursor: ThemeColor{Foreground: "black", Background: "cyan", mono: goncurses.A_REVERSE},
+		Match:  ThemeColor{Foreground: "green", Bold: true, mono: goncurses.A_BOLD | goncurses.A_UNDERLINE},
+		Error:  ThemeColor{Foreground: "red", Bold: true, mono: goncurses.A_BOLD},
+		Prompt: ThemeColor{Foreground: "yellow", mono: goncurses.A_BOLD},
+		Series: []ThemeColor{
+			{Foreground: "green"},
+			{Foreground: "blue"},
+			{Foreground: "magenta"},
+			{Foreground: "yellow"},
+		},
+	}
+}
+
+/* Load theme from config directory, return default theme if file not exist */
+func LoadTheme() (*Theme, error) {
+	theme := DefaultTheme()
+	_, err := LoadConfigFile(ThemeFile, theme)
+
+	if err != nil {
+		return DefaultTheme(), fmt.Errorf("Can't load theme: %s", err.Error())
+	}
+
+	for _, color := range theme.roles() {
+		for _, name := range []string{color.Foreground, color.Background} {
+			if _, exist := themeColorNames[name]; !exist {
+				return DefaultTheme(), fmt.Errorf("Can't load theme: unknown color '%s'", name)
+			}
+		}
+	}
+
+	return theme, nil
+}
+
+/* All roles of theme, series included */
+func (self *Theme) roles() []*ThemeColor {
+	result := []*ThemeColor{&self.Border, &self.Focus, &self.Title, &self.Cursor, &self.Match, &self.Error, &self.Prompt}
+
+	for ind := range self.Series {
+		result = append(result, &self.Series[ind])
+	}
+
+	return result
+}
+
+/*
+Start colors and init color pair for each role, must be called after goncurses.Init
+Roles which can't get color pair (more roles than COLOR_PAIRS for example) use monochrome attributes,
+error about them is returned, but theme is usable
+*/
+func (self *Theme) Init() error {
+	var result error
+	var failed int
+	roles := self.roles()
+	self.Colors = goncurses.HasColors() && goncurses.StartColor() == nil
+
+	if !self.Colors {
+		for _, color := range roles {
+			color.attr = color.mono
+		}
+
+		return nil
+	}
+
+	defaultFg, defaultBg := defaultColor, defaultColor
+
+	if goncurses.UseDefaultColors() != nil {
+		defaultFg, defaultBg = goncurses.C_WHITE, goncurses.C_BLACK
+	}
+
+	for ind, color := range roles {
+		pair := int16(ind + 1)
+		fg, bg := themeColorNames[color.Foreground], themeColorNames[color.Background]
+
+		if fg == defaultColor {
+			fg = defaultFg
+		}
+
+		if bg == defaultColor {
+			bg = defaultBg
+		}
+
+		err := goncurses.InitPair(pair, fg, bg)
+
+		if err != nil {
+			color.attr = color.mono
+			failed++
+			result = fmt.Errorf("Can't init colors for %d of %d roles of theme, monochrome used for them: %s", failed, len(roles), err.Error())
+			continue
+		}
+
+		color.attr = goncurses.ColorPair(pair)
+
+		if color.Bold {
+			color.attr |= goncurses.A_BOLD
+		}
+	}
+
+	return result
+}
+
+/* Attributes of widgets by roles of theme */
+func (self *Theme) WidgetStyle() widget.Style {
+	return widget.Style{
+		Title:  self.Title.attr,
+		Cursor: self.Cursor.attr,
+		Error:  self.Error.attr,
+		Prompt: self.Prompt.attr,
+		Fill:   self.Cursor.attr,
+		Match:  self.Match.attr,
+	}
+}
+
+/* Attribute of series by index, series colors are repeated if there are less of them */
+func (self *Theme) SeriesAttr(ind int) goncurses.Char {
+	if len(self.Series) == 0 {
+		return goncurses.A_NORMAL
+	}
+
+	return self.Series[ind%len(self.Series)].attr
+}
+
+/* Draw box around window by border color */
+func (self *Theme) Box(win *goncurses.Window) {
+	self.BoxColor(win, self.Border)
+}
+
+/* Draw box around window by given color */
+func (self *Theme) BoxColor(win *goncurses.Window, color ThemeColor) {
+	attr := color.attr
+	win.Border(
+		goncurses.ACS_VLINE|attr, goncurses.ACS_VLINE|attr, goncurses.ACS_HLINE|attr, goncurses.ACS_HLINE|attr,
+		goncurses.ACS_ULCORNER|attr, goncurses.ACS_URCORNER|attr, goncurses.ACS_LLCORNER|attr, goncurses.ACS_LRCORNER|attr,
+	)
+}
+
+/* Draw box and title centered at top border */
+func (self *Theme) BoxWithTitle(win *goncurses.Window, title string) {
+	_, sizeX := win.MaxYX()
+	self.Box(win)
+	self.Title.Print(win, 0, sizeX/2-(len(title)/2), title)
+}
+
+/* Attribute of role for AttrOn/AttrOff */
+func (self ThemeColor) Attr() goncurses.Char {
+	return self.attr
+}
+
+/* Print text at y, x with attribute of role */
+func (self ThemeColor) Print(win *goncurses.Window, y, x int, text string) {
+	win.AttrOn(self.attr)
+	win.MovePrint(y, x, text)
+	win.AttrOff(self.attr)
+}
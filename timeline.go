@@ -0,0 +1,206 @@
+/*
+Timeline window for whole portfolio horizon until last maturity
+Periods are quarters or years, chart shows cumulative coupon income and cumulative principal return
+*/
+package main
+
+import (
+	"bonds_payment_calendar/bonds"
+	"fmt"
+	"time"
+
+	"github.com/gbin/goncurses"
+)
+
+const (
+	timelineColumnWidth = 7
+	timelineAxisWidth   = 9
+	incomeMarker        = '*'
+	principalMarker     = '#'
+	bothMarker          = '@'
+)
+
+/* Sums of payments for one period of timeline */
+type TimelinePeriod struct {
+	Label     string
+	Income    float64 // Coupons in period
+	Principal float64 // Redemptions in period
+}
+
+type TimelineView struct {
+	Window     *FSMWindow
+	ByYear     bool // Periods are years, quarters otherwise
+	FirstShown int  // Index of first shown period
+}
+
+func TimelineViewNew(sizeY, sizeX, posY, posX int) (*TimelineView, error) {
+	window, err := FSMWindowNew(sizeY, sizeX, posY, posX)
+
+	if err != nil {
+		return nil, err
+	}
+
+	view := new(TimelineView)
+	view.Window = window
+	window.Window.Keypad(true)
+
+	scroll := func(step func() int) SpecialInputFunc {
+		return func() bool {
+			view.FirstShown = max(view.FirstShown+step(), 0)
+			return true
+		}
+	}
+
+	window.SetTitle("|Timeline|").SetCustomDraw(view.draw).
+		RegisterAction(ActionLeft, scroll(func() int { return -1 })).
+		RegisterAction(ActionRight, scroll(func() int { return 1 })).
+		RegisterAction(ActionPrev, scroll(func() int { return -view.visibleColumns() })).
+		RegisterAction(ActionNext, scroll(func() int { return view.visibleColumns() })).
+		RegisterAction(ActionGranularity, func() bool {
+			view.ByYear = !view.ByYear
+			view.FirstShown = 0
+			return true
+		})
+
+	return view, nil
+}
+
+/* Split payments from now until last one into quarters or years */
+func TimelinePeriods(payments []bonds.Payment, byYear bool) []TimelinePeriod {
+	result := make([]TimelinePeriod, 0)
+
+	if len(payments) == 0 {
+		return result
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), time.Month((int(now.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+	var months int = 3
+
+	if byYear {
+		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		months = 12
+	}
+
+	last := payments[len(payments)-1].Date
+	var ind int
+
+	for periodStart := start; !periodStart.After(last); periodStart = periodStart.AddDate(0, months, 0) {
+		period := TimelinePeriod{Label: fmt.Sprint(periodStart.Year())}
+
+		if !byYear {
+			period.Label = fmt.Sprintf("%02dQ%d", periodStart.Year()%100, (int(periodStart.Month())-1)/3+1)
+		}
+
+		periodEnd := periodStart.AddDate(0, months, 0)
+
+		for ; ind < len(payments) && payments[ind].Date.Before(periodEnd); ind++ {
+			if payments[ind].Type == bonds.RedemptionFlow {
+				period.Principal += payments[ind].Amount
+			} else {
+				period.Income += payments[ind].Amount
+			}
+		}
+
+		result = append(result, period)
+	}
+
+	return result
+}
+
+/* Count of period columns fit in window */
+func (self *TimelineView) visibleColumns() int {
+	return max((self.Window.SizeX-timelineAxisWidth-2)/timelineColumnWidth, 1)
+}
+
+func (self *TimelineView) draw() {
+	win := self.Window.Window
+	sizeY, sizeX := self.Window.SizeY, self.Window.SizeX
+	payments := GraphBonds().PaymentsWhere(func(flow bonds.CashFlow) bool {
+		return !flow.Date.Before(today())
+	})
+	periods := TimelinePeriods(payments, self.ByYear)
+	visible := self.visibleColumns()
+	self.FirstShown = max(min(self.FirstShown, len(periods)-visible), 0)
+
+	var legendY int = sizeY - 2
+	var incomeY int = sizeY - 3 // Row with sum of payments in each period
+	var labelY int = sizeY - 4
+	var chartTop int = 1
+	var chartBottom int = sizeY - 5
+	chartHeight := chartBottom - chartTop + 1
+
+	granularity := "quarters"
+
+	if self.ByYear {
+		granularity = "years"
+	}
+
+	legend := fmt.Sprintf(
+		"%c income %c principal (cumulative) | %s:%s %s,%s,%s,%s:scroll",
+		incomeMarker, principalMarker, ActiveKeymap.Name(ActionGranularity), granularity,
+		ActiveKeymap.Name(ActionLeft), ActiveKeymap.Name(ActionRight), ActiveKeymap.Name(ActionPrev), ActiveKeymap.Name(ActionNext),
+	)
+	win.MovePrint(legendY, 1, legend[:min(len(legend), sizeX-2)])
+
+	if len(periods) == 0 || chartHeight < 2 {
+		win.MovePrint(chartTop, 1, "No future payments")
+		return
+	}
+
+	cumulativeIncome := make([]float64, len(periods))
+	cumulativePrincipal := make([]float64, len(periods))
+	var income, principal float64
+
+	for ind, period := range periods {
+		income += period.Income
+		principal += period.Principal
+		cumulativeIncome[ind] = income
+		cumulativePrincipal[ind] = principal
+	}
+
+	maxValue := max(income, principal)
+
+	if maxValue <= 0 {
+		win.MovePrint(chartTop, 1, "No amounts, set couponValue and faceValue of bonds")
+		return
+	}
+
+	win.MovePrint(chartTop, 1, FormatCompact(maxValue))
+	win.MovePrint(chartTop+chartHeight/2, 1, FormatCompact(maxValue/2))
+	win.MovePrint(chartBottom, 1, "0")
+	win.VLine(chartTop, timelineAxisWidth-1, goncurses.ACS_VLINE, chartHeight)
+	win.MovePrint(incomeY, 1, "Paid")
+
+	rowOf := func(value float64) int {
+		return chartBottom - int(value/maxValue*float64(chartHeight-1)+0.5)
+	}
+
+	for column := 0; column < visible && self.FirstShown+column < len(periods); column++ {
+		ind := self.FirstShown + column
+		labelX := timelineAxisWidth + column*timelineColumnWidth
+		incomeRow, principalRow := rowOf(cumulativeIncome[ind]), rowOf(cumulativePrincipal[ind])
+
+		for x := labelX; x < labelX+timelineColumnWidth-1; x++ {
+			if incomeRow == principalRow {
+				win.MoveAddChar(incomeRow, x, bothMarker)
+			} else {
+				win.MoveAddChar(incomeRow, x, incomeMarker|ActiveTheme.SeriesAttr(0))
+				win.MoveAddChar(principalRow, x, principalMarker|ActiveTheme.SeriesAttr(1))
+			}
+		}
+
+		win.MovePrint(labelY, labelX, periods[ind].Label)
+		win.MovePrint(incomeY, labelX, FormatCompact(periods[ind].Income+periods[ind].Principal))
+	}
+
+	if self.FirstShown > 0 {
+		win.MovePrint(labelY, timelineAxisWidth-2, "<")
+	}
+
+	if self.FirstShown+visible < len(periods) {
+		win.MovePrint(labelY, sizeX-2, ">")
+	}
+
+	self.Window.Title = fmt.Sprintf("|Timeline: until %s, total %s|", payments[len(payments)-1].Date.Format(DefaultDateLayout), FormatCompact(income+principal))
+}
@@ -0,0 +1,114 @@
+package widget
+
+import (
+	"github.com/gbin/goncurses"
+)
+
+const (
+	confirmYes = "[ Yes ]"
+	confirmNo  = "[ No ]"
+	confirmGap = 2
+)
+
+/*
+Question with yes/no buttons, answered by 'y'/'n', buttons or mouse
+Cancel answers no, 'No' is focused by default
+*/
+type Confirm struct {
+	Question string
+	OnAnswer func(yes bool) // Called before answering input returns ResultClose, so owner has not closed widget yet
+
+	yes     bool // 'Yes' button focused
+	width   int  // Width of area at last draw, for mouse
+	buttonY int  // Row of buttons at last draw, relative to area
+}
+
+func ConfirmNew(question string, onAnswer func(yes bool)) *Confirm {
+	obj := new(Confirm)
+	obj.Question = question
+	obj.OnAnswer = onAnswer
+
+	return obj
+}
+
+func (self *Confirm) answer(yes bool) Result {
+	if self.OnAnswer != nil {
+		self.OnAnswer(yes)
+	}
+
+	return ResultClose
+}
+
+/* X of first button, buttons are centered */
+func (self *Confirm) buttonsX(width int) int {
+	return max((width-Width(confirmYes)-confirmGap-Width(confirmNo))/2, 0)
+}
+
+/* Wrapped question, empty line and buttons */
+func (self *Confirm) Size(maxY, maxX int) (int, int) {
+	sizeX := min(max(Width(self.Question), Width(confirmYes)+confirmGap+Width(confirmNo)), maxX)
+	return min(len(Wrap(self.Question, sizeX))+2, maxY), sizeX
+}
+
+func (self *Confirm) Draw(win *goncurses.Window, area Rect) {
+	lines := Wrap(self.Question, area.SizeX)
+	self.width = area.SizeX
+	self.buttonY = min(len(lines)+1, area.SizeY-1)
+
+	for y := 0; y < len(lines) && y < self.buttonY; y++ {
+		Print(win, area.PosY+y, area.PosX, Clip(lines[y], area.SizeX), ActiveStyle.Prompt)
+	}
+
+	x := area.PosX + self.buttonsX(area.SizeX)
+	yesAttr, noAttr := goncurses.Char(goncurses.A_NORMAL), ActiveStyle.Cursor
+
+	if self.yes {
+		yesAttr, noAttr = noAttr, yesAttr
+	}
+
+	Print(win, area.PosY+self.buttonY, x, confirmYes, yesAttr)
+	Print(win, area.PosY+self.buttonY, x+Width(confirmYes)+confirmGap, confirmNo, noAttr)
+}
+
+func (self *Confirm) Input(command Command, key goncurses.Key) Result {
+	switch command {
+	case CommandLeft, CommandRight, CommandNext:
+		self.yes = !self.yes
+
+	case CommandSelect:
+		return self.answer(self.yes)
+
+	case CommandCancel:
+		return self.answer(false)
+	}
+
+	switch key {
+	case 'y', 'Y':
+		return self.answer(true)
+
+	case 'n', 'N':
+		return self.answer(false)
+	}
+
+	return ResultNone
+}
+
+/* Click on button answers */
+func (self *Confirm) Mouse(y, x int, wheel int, clicks int) Result {
+	if clicks == 0 || y != self.buttonY {
+		return ResultNone
+	}
+
+	yesX := self.buttonsX(self.width)
+	noX := yesX + Width(confirmYes) + confirmGap
+
+	switch {
+	case x >= yesX && x < yesX+Width(confirmYes):
+		return self.answer(true)
+
+	case x >= noX && x < noX+Width(confirmNo):
+		return self.answer(false)
+	}
+
+	return ResultNone
+}
@@ -0,0 +1,282 @@
+package widget
+
+import (
+	"github.com/gbin/goncurses"
+)
+
+const (
+	DefaultInputWidth = 30
+	DefaultSubmitText = "Save"
+
+	keyBackspace = 127
+	keyCtrlH     = 8
+	keyCtrlU     = 21 // Clear value of field
+)
+
+/* Field of form, validated when focus leaves it and before submit */
+type Field struct {
+	Label    string
+	Value    string
+	Hint     string                   // Shown under form while field is focused
+	Validate func(value string) error // Nil accepts any value
+	Err      error                    // Error of last validation
+}
+
+/*
+Fields with labels and submit button under them
+Up/down and next move focus, select on field moves to next one, select on button submits form
+Invalid fields are marked by '!', error of focused field shown under form
+*/
+type Form struct {
+	Fields     []*Field
+	Focused    int                    // Index of focused field, len(Fields) is submit button
+	SubmitText string                 // Label of submit button
+	InputWidth int                    // Width of values
+	OnSubmit   func(form *Form) error // Called when all fields are valid, error keeps form opened and shown under it
+	OnChange   func(form *Form)       // Called after each change of value
+
+	err     error
+	top     int         // First visible field, if form is higher than area
+	visible int         // Count of visible fields at last draw
+	text    TextDecoder // Chars of values, UTF-8 keys come by one byte
+}
+
+func FormNew(fields ...*Field) *Form {
+	obj := new(Form)
+	obj.Fields = fields
+	obj.SubmitText = DefaultSubmitText
+	obj.InputWidth = DefaultInputWidth
+
+	return obj
+}
+
+/* Form reads printable keys into values */
+func (self *Form) EditsText() bool {
+	return true
+}
+
+/* Return value of field with label, empty if not exist */
+func (self *Form) Value(label string) string {
+	for _, field := range self.Fields {
+		if field.Label == label {
+			return field.Value
+		}
+	}
+
+	return ""
+}
+
+/* Validate field by index, return true if valid */
+func (self *Form) validate(ind int) bool {
+	if ind < 0 || ind >= len(self.Fields) {
+		return true
+	}
+
+	field := self.Fields[ind]
+	field.Err = nil
+
+	if field.Validate != nil {
+		field.Err = field.Validate(field.Value)
+	}
+
+	return field.Err == nil
+}
+
+/* Validate current field and move focus, next from button goes to first field */
+func (self *Form) moveFocus(step int) {
+	self.validate(self.Focused)
+	self.text.Reset()
+	self.Focused = (self.Focused + step + len(self.Fields) + 1) % (len(self.Fields) + 1)
+}
+
+/* Validate all fields and call OnSubmit, focus first invalid field if any */
+func (self *Form) Submit() Result {
+	self.err = nil
+
+	for ind := range self.Fields {
+		if !self.validate(ind) {
+			self.Focused = ind
+			return ResultNone
+		}
+	}
+
+	if self.OnSubmit != nil {
+		self.err = self.OnSubmit(self)
+	}
+
+	if self.err != nil {
+		return ResultNone
+	}
+
+	return ResultClose
+}
+
+/* Width of longest label */
+func (self *Form) labelWidth() int {
+	var result int
+
+	for _, field := range self.Fields {
+		result = max(result, Width(field.Label))
+	}
+
+	return result
+}
+
+/* Message under form: error of focused field, error of submit or hint */
+func (self *Form) message() (string, goncurses.Char) {
+	if self.Focused < len(self.Fields) && self.Fields[self.Focused].Err != nil {
+		return self.Fields[self.Focused].Err.Error(), ActiveStyle.Error
+	}
+
+	if self.err != nil {
+		return self.err.Error(), ActiveStyle.Error
+	}
+
+	if self.Focused < len(self.Fields) {
+		return self.Fields[self.Focused].Hint, goncurses.A_NORMAL
+	}
+
+	return "", goncurses.A_NORMAL
+}
+
+/* Fields, empty line, button and message line */
+func (self *Form) Size(maxY, maxX int) (int, int) {
+	sizeX := 1 + self.labelWidth() + 2 + self.InputWidth
+
+	for _, field := range self.Fields {
+		sizeX = max(sizeX, Width(field.Hint))
+	}
+
+	return min(len(self.Fields)+3, maxY), min(sizeX, maxX)
+}
+
+func (self *Form) Draw(win *goncurses.Window, area Rect) {
+	labelWidth := self.labelWidth()
+	inputX := area.PosX + 1 + labelWidth + 2
+	inputWidth := min(self.InputWidth, area.PosX+area.SizeX-inputX)
+	self.visible = min(max(area.SizeY-3, 1), len(self.Fields))
+	self.top = scrollTo(self.top, min(self.Focused, len(self.Fields)-1), len(self.Fields), self.visible)
+
+	for y := 0; y < self.visible && self.top+y < len(self.Fields); y++ {
+		ind := self.top + y
+		field := self.Fields[ind]
+
+		if field.Err != nil {
+			PrintLine(win, area.PosY+y, area.PosX, 1, "!", ActiveStyle.Error)
+		}
+
+		win.MovePrint(area.PosY+y, area.PosX+1, Clip(field.Label, labelWidth)+":")
+		value := []rune(field.Value)
+		attr := goncurses.A_NORMAL
+
+		if ind == self.Focused {
+			value = append(value, '_')
+			attr = ActiveStyle.Prompt
+		}
+
+		// Tail of long value is shown, because it is edited at end
+		value = value[max(len(value)-inputWidth, 0):]
+		PrintLine(win, area.PosY+y, inputX, inputWidth, string(value), attr)
+	}
+
+	buttonY := area.PosY + self.visible + 1
+	button := "[ " + self.SubmitText + " ]"
+
+	if self.Focused == len(self.Fields) {
+		PrintLine(win, buttonY, inputX, Width(button), button, ActiveStyle.Cursor)
+	} else {
+		win.MovePrint(buttonY, inputX, button)
+	}
+
+	message, attr := self.message()
+	PrintLine(win, buttonY+1, area.PosX, area.SizeX, message, attr)
+}
+
+/* Edit value of focused field by raw key */
+func (self *Form) edit(key goncurses.Key) {
+	if self.Focused >= len(self.Fields) {
+		return
+	}
+
+	field := self.Fields[self.Focused]
+	value := []rune(field.Value)
+	char, isText := self.text.Decode(key)
+
+	switch {
+	case key == goncurses.KEY_BACKSPACE || key == keyBackspace || key == keyCtrlH:
+		if len(value) == 0 {
+			return
+		}
+
+		field.Value = string(value[:len(value)-1])
+
+	case key == keyCtrlU:
+		field.Value = ""
+
+	case isText:
+		field.Value += string(char)
+
+	default:
+		return
+	}
+
+	field.Err = nil
+
+	if self.OnChange != nil {
+		self.OnChange(self)
+	}
+}
+
+func (self *Form) Input(command Command, key goncurses.Key) Result {
+	switch command {
+	case CommandUp:
+		self.moveFocus(-1)
+
+	case CommandDown, CommandNext:
+		self.moveFocus(1)
+
+	case CommandSelect:
+		if self.Focused == len(self.Fields) {
+			return self.Submit()
+		}
+
+		self.moveFocus(1)
+
+	case CommandCancel:
+		return ResultClose
+
+	case CommandNone:
+		self.edit(key)
+	}
+
+	return ResultNone
+}
+
+/* Click on field focuses it, click on button submits form, wheel moves focus */
+func (self *Form) Mouse(y, x int, wheel int, clicks int) Result {
+	if wheel != 0 {
+		self.moveFocus(wheel / max(abs(wheel), 1))
+		return ResultNone
+	}
+
+	if clicks == 0 {
+		return ResultNone
+	}
+
+	if ind := self.top + y; y >= 0 && y < self.visible && ind < len(self.Fields) {
+		self.validate(self.Focused)
+		self.Focused = ind
+		return ResultNone
+	}
+
+	if y == self.visible+1 {
+		self.Focused = len(self.Fields)
+		return self.Submit()
+	}
+
+	return ResultNone
+}
+
+func abs(value int) int {
+	return max(value, -value)
+}
@@ -0,0 +1,126 @@
+package widget
+
+import (
+	"github.com/gbin/goncurses"
+)
+
+/*
+Scrollable list of lines
+If Selectable, cursor is shown and Select passes index of item under cursor into OnSelect
+*/
+type List struct {
+	Items      []string
+	Selectable bool
+	Cursor     int
+	OnSelect   func(ind int) Result // Return ResultClose for close list after choosing
+	Marked     func(ind int) bool   // Items drawn with Match style, optional
+
+	top    int
+	height int // Height of area at last draw, for moving by page
+}
+
+func ListNew(items []string, selectable bool) *List {
+	obj := new(List)
+	obj.Items = items
+	obj.Selectable = selectable
+
+	return obj
+}
+
+func (self *List) Size(maxY, maxX int) (int, int) {
+	var sizeX int
+
+	for _, item := range self.Items {
+		sizeX = max(sizeX, Width(item))
+	}
+
+	return min(len(self.Items), maxY), min(sizeX, maxX)
+}
+
+func (self *List) Draw(win *goncurses.Window, area Rect) {
+	self.height = area.SizeY
+	cursor := -1
+
+	if self.Selectable {
+		self.Cursor = max(min(self.Cursor, len(self.Items)-1), 0)
+		cursor = self.Cursor
+	}
+
+	self.top = scrollTo(self.top, cursor, len(self.Items), area.SizeY)
+
+	for y := 0; y < area.SizeY && self.top+y < len(self.Items); y++ {
+		row := self.top + y
+
+		if row == cursor {
+			PrintLine(win, area.PosY+y, area.PosX, area.SizeX, self.Items[row], ActiveStyle.Cursor)
+		} else if self.Marked != nil && self.Marked(row) {
+			PrintLine(win, area.PosY+y, area.PosX, area.SizeX, self.Items[row], ActiveStyle.Match)
+		} else {
+			win.MovePrint(area.PosY+y, area.PosX, Clip(self.Items[row], area.SizeX))
+		}
+	}
+}
+
+/* Move cursor if list is selectable, scroll otherwise */
+func (self *List) Move(step int) {
+	if self.Selectable {
+		self.Cursor = max(min(self.Cursor+step, len(self.Items)-1), 0)
+	} else {
+		self.top = max(self.top+step, 0)
+	}
+}
+
+func (self *List) selectCursor() Result {
+	if !self.Selectable || self.OnSelect == nil || len(self.Items) == 0 {
+		return ResultNone
+	}
+
+	return self.OnSelect(self.Cursor)
+}
+
+func (self *List) Input(command Command, key goncurses.Key) Result {
+	switch command {
+	case CommandUp:
+		self.Move(-1)
+
+	case CommandDown:
+		self.Move(1)
+
+	case CommandPageUp:
+		self.Move(-max(self.height, 1))
+
+	case CommandPageDown:
+		self.Move(max(self.height, 1))
+
+	case CommandSelect:
+		return self.selectCursor()
+
+	case CommandCancel:
+		return ResultClose
+	}
+
+	return ResultNone
+}
+
+/* Wheel scrolls, click moves cursor to row, click on row under cursor or double click selects it */
+func (self *List) Mouse(y, x int, wheel int, clicks int) Result {
+	if wheel != 0 {
+		self.Move(wheel)
+		return ResultNone
+	}
+
+	row := self.top + y
+
+	if clicks == 0 || !self.Selectable || y < 0 || y >= self.height || row >= len(self.Items) {
+		return ResultNone
+	}
+
+	open := IsOpenClick(clicks, row == self.Cursor)
+	self.Cursor = row
+
+	if open {
+		return self.selectCursor()
+	}
+
+	return ResultNone
+}
@@ -0,0 +1,72 @@
+package widget
+
+import (
+	"fmt"
+
+	"github.com/gbin/goncurses"
+)
+
+/* One line bar filled by ratio of value to total, with label before and numbers after it */
+type ProgressBar struct {
+	Label        string
+	Value, Total float64
+	Format       string // Format of value and total after bar, '%.0f/%.0f' by default
+}
+
+func ProgressBarNew(label string, value, total float64) *ProgressBar {
+	obj := new(ProgressBar)
+	obj.Label = label
+	obj.Value = value
+	obj.Total = total
+	obj.Format = "%.0f/%.0f"
+
+	return obj
+}
+
+/* Filled part from 0 to 1 */
+func (self *ProgressBar) Ratio() float64 {
+	if self.Total <= 0 {
+		return 0
+	}
+
+	return max(min(self.Value/self.Total, 1), 0)
+}
+
+func (self *ProgressBar) Size(maxY, maxX int) (int, int) {
+	return min(1, maxY), maxX
+}
+
+func (self *ProgressBar) Draw(win *goncurses.Window, area Rect) {
+	numbers := fmt.Sprintf(" "+self.Format+" %3.0f%%", self.Value, self.Total, self.Ratio()*100)
+	label := self.Label
+
+	if label != "" {
+		label += " "
+	}
+
+	barWidth := area.SizeX - Width(label) - Width(numbers) - 2
+
+	if barWidth < 1 {
+		win.MovePrint(area.PosY, area.PosX, Clip(label+numbers, area.SizeX))
+		return
+	}
+
+	filled := int(self.Ratio()*float64(barWidth) + 0.5)
+	win.MovePrint(area.PosY, area.PosX, label+"[")
+	x := area.PosX + Width(label) + 1
+	PrintLine(win, area.PosY, x, filled, "", ActiveStyle.Fill)
+	PrintLine(win, area.PosY, x+filled, barWidth-filled, "", goncurses.A_NORMAL)
+	win.MovePrint(area.PosY, x+barWidth, "]"+numbers)
+}
+
+func (self *ProgressBar) Input(command Command, key goncurses.Key) Result {
+	if command == CommandSelect || command == CommandCancel {
+		return ResultClose
+	}
+
+	return ResultNone
+}
+
+func (self *ProgressBar) Mouse(y, x int, wheel int, clicks int) Result {
+	return ResultNone
+}
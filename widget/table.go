@@ -0,0 +1,135 @@
+package widget
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gbin/goncurses"
+)
+
+/* Column of table, width calculated by content if zero */
+type Column struct {
+	Title string
+	Width int
+	Right bool // Align values to right, for numbers
+}
+
+/* List with header of columns and optional footer, rows are aligned by columns */
+type Table struct {
+	*List
+	Columns []Column
+	Rows    [][]string
+	Footer  string // Line under rows, totals for example
+}
+
+func TableNew(columns []Column, rows [][]string, selectable bool) *Table {
+	obj := new(Table)
+	obj.Columns = columns
+	obj.Rows = rows
+	obj.List = ListNew(nil, selectable)
+	obj.update()
+
+	return obj
+}
+
+/* Widths of columns, fixed or by longest value */
+func (self *Table) widths() []int {
+	result := make([]int, len(self.Columns))
+
+	for ind, column := range self.Columns {
+		result[ind] = column.Width
+
+		if column.Width != 0 {
+			continue
+		}
+
+		result[ind] = Width(column.Title)
+
+		for _, row := range self.Rows {
+			if ind < len(row) {
+				result[ind] = max(result[ind], Width(row[ind]))
+			}
+		}
+	}
+
+	return result
+}
+
+/* Format one row by widths of columns */
+func (self *Table) format(row []string, widths []int) string {
+	cells := make([]string, len(widths))
+
+	for ind, width := range widths {
+		var value string
+
+		if ind < len(row) {
+			value = Clip(row[ind], width)
+		}
+
+		if self.Columns[ind].Right {
+			cells[ind] = fmt.Sprintf("%*s", width, value)
+		} else {
+			cells[ind] = fmt.Sprintf("%-*s", width, value)
+		}
+	}
+
+	return strings.TrimRight(strings.Join(cells, "  "), " ")
+}
+
+/* Format rows into items of list, must be called after changing Rows */
+func (self *Table) update() {
+	widths := self.widths()
+	self.List.Items = make([]string, 0, len(self.Rows))
+
+	for _, row := range self.Rows {
+		self.List.Items = append(self.List.Items, self.format(row, widths))
+	}
+}
+
+/* Replace rows, cursor is kept if possible */
+func (self *Table) SetRows(rows [][]string) {
+	self.Rows = rows
+	self.update()
+}
+
+/* Count of lines above and under rows */
+func (self *Table) decorations() int {
+	if self.Footer != "" {
+		return 2
+	}
+
+	return 1
+}
+
+func (self *Table) Size(maxY, maxX int) (int, int) {
+	sizeY, sizeX := self.List.Size(maxY-self.decorations(), maxX)
+	header := self.format(self.titles(), self.widths())
+	sizeX = max(sizeX, min(max(Width(header), Width(self.Footer)), maxX))
+
+	return min(sizeY+self.decorations(), maxY), sizeX
+}
+
+func (self *Table) titles() []string {
+	result := make([]string, len(self.Columns))
+
+	for ind, column := range self.Columns {
+		result[ind] = column.Title
+	}
+
+	return result
+}
+
+func (self *Table) Draw(win *goncurses.Window, area Rect) {
+	PrintLine(win, area.PosY, area.PosX, area.SizeX, self.format(self.titles(), self.widths()), ActiveStyle.Title)
+	rows := Rect{area.SizeY - self.decorations(), area.SizeX, area.PosY + 1, area.PosX}
+	self.List.Draw(win, rows)
+
+	if self.Footer != "" {
+		win.MovePrint(area.PosY+area.SizeY-1, area.PosX, Clip(self.Footer, area.SizeX))
+	}
+}
+
+/* Same as List.Mouse, but header line is skipped */
+func (self *Table) Mouse(y, x int, wheel int, clicks int) Result {
+	return self.List.Mouse(y-1, x, wheel, clicks)
+}
@@ -0,0 +1,128 @@
+package widget
+
+import (
+	"strings"
+
+	"github.com/gbin/goncurses"
+)
+
+/* Scrollable text, each line is wrapped by words to width of area */
+type TextView struct {
+	Lines []string // Lines of text, may contain '\n'
+
+	top    int // First visible wrapped line
+	height int // Height of area at last draw, for scrolling by page
+	width  int // Width of area at last draw
+}
+
+func TextViewNew(lines ...string) *TextView {
+	obj := new(TextView)
+	obj.Lines = make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		obj.Lines = append(obj.Lines, strings.Split(line, "\n")...)
+	}
+
+	return obj
+}
+
+/*
+Split line into lines not longer than width, breaking by spaces
+Words longer than width are broken at width, leading spaces of line are kept for indent
+*/
+func Wrap(line string, width int) []string {
+	runes := []rune(line)
+
+	if width <= 0 || len(runes) <= width {
+		return []string{line}
+	}
+
+	result := make([]string, 0)
+
+	for len(runes) > width {
+		cut := width
+
+		for ind := width; ind > 0; ind-- {
+			if runes[ind] == ' ' {
+				cut = ind
+				break
+			}
+		}
+
+		result = append(result, strings.TrimRight(string(runes[:cut]), " "))
+		runes = runes[cut:]
+
+		for len(runes) > 0 && runes[0] == ' ' {
+			runes = runes[1:]
+		}
+	}
+
+	if len(runes) > 0 {
+		result = append(result, string(runes))
+	}
+
+	return result
+}
+
+/* All lines wrapped to width */
+func (self *TextView) wrapped(width int) []string {
+	result := make([]string, 0, len(self.Lines))
+
+	for _, line := range self.Lines {
+		result = append(result, Wrap(line, width)...)
+	}
+
+	return result
+}
+
+func (self *TextView) Size(maxY, maxX int) (int, int) {
+	var sizeX int
+
+	for _, line := range self.Lines {
+		sizeX = max(sizeX, Width(line))
+	}
+
+	sizeX = min(sizeX, maxX)
+	return min(len(self.wrapped(sizeX)), maxY), sizeX
+}
+
+func (self *TextView) Draw(win *goncurses.Window, area Rect) {
+	lines := self.wrapped(area.SizeX)
+	self.height, self.width = area.SizeY, area.SizeX
+	self.top = scrollTo(self.top, -1, len(lines), area.SizeY)
+
+	for y := 0; y < area.SizeY && self.top+y < len(lines); y++ {
+		win.MovePrint(area.PosY+y, area.PosX, Clip(lines[self.top+y], area.SizeX))
+	}
+}
+
+/* Scroll by given count of lines, position is clamped on next draw */
+func (self *TextView) Scroll(step int) {
+	self.top = max(self.top+step, 0)
+}
+
+func (self *TextView) Input(command Command, key goncurses.Key) Result {
+	switch command {
+	case CommandUp:
+		self.Scroll(-1)
+
+	case CommandDown:
+		self.Scroll(1)
+
+	case CommandPageUp:
+		self.Scroll(-max(self.height, 1))
+
+	case CommandPageDown:
+		self.Scroll(max(self.height, 1))
+
+	case CommandSelect, CommandCancel:
+		return ResultClose
+	}
+
+	return ResultNone
+}
+
+func (self *TextView) Mouse(y, x int, wheel int, clicks int) Result {
+	self.Scroll(wheel)
+	return ResultNone
+}
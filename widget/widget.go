@@ -0,0 +1,184 @@
+/*
+Reusable widgets drawn inside of ncurses window
+Widget knows nothing about keymap and windows stack:
+    owner translates keys into Command and passes them into Input
+    owner draws widget into area of own window, so several widgets can share one window
+Widgets:
+    TextView - text with word wrap and scrolling
+    List - scrollable lines with optional selection
+    Table - list with columns and header
+    Form - fields with labels, inline validation and text editing
+    Confirm - question with yes/no answer
+    ProgressBar - bar filled by ratio of value to total
+*/
+package widget
+
+import (
+	"unicode/utf8"
+
+	"github.com/gbin/goncurses"
+)
+
+/* Size and position of area, relative to window or screen */
+type Rect struct {
+	SizeY, SizeX int
+	PosY, PosX   int
+}
+
+/* Generic input of widget, translated from keys by owner */
+type Command int
+
+const (
+	CommandNone     Command = iota // Key not bound to command, widget may use key itself
+	CommandUp                      // Move cursor or scroll by one line
+	CommandDown                    //
+	CommandLeft                    //
+	CommandRight                   //
+	CommandPageUp                  // Move cursor or scroll by page
+	CommandPageDown                //
+	CommandSelect                  // Choose item under cursor, submit form
+	CommandCancel                  // Close widget without choosing
+	CommandNext                    // Move focus to next field
+)
+
+/* Result of input, tells owner what to do with widget */
+type Result int
+
+const (
+	ResultNone  Result = iota // Widget stays opened
+	ResultClose               // Widget finished, owner must close it
+)
+
+type Widget interface {
+	Size(maxY, maxX int) (int, int)                  // Preferred size of content, not bigger than given
+	Draw(win *goncurses.Window, area Rect)           // Draw content into area of window
+	Input(command Command, key goncurses.Key) Result // Process command, key is raw key of command
+	Mouse(y, x int, wheel int, clicks int) Result    // Process mouse inside of area, y and x relative to area
+}
+
+/* Widget reads printable keys as text, owner must not translate printable keys into commands */
+type TextInput interface {
+	EditsText() bool
+}
+
+/* Attributes of widgets, set by owner from color theme */
+type Style struct {
+	Title  goncurses.Char // Headers of tables
+	Cursor goncurses.Char // Selected row, focused button
+	Error  goncurses.Char // Validation errors
+	Prompt goncurses.Char // Focused input field, questions
+	Fill   goncurses.Char // Filled part of progress bar
+	Match  goncurses.Char // Marked rows, search matches for example
+}
+
+var (
+	ActiveStyle = Style{
+		Title:  goncurses.A_BOLD,
+		Cursor: goncurses.A_REVERSE,
+		Error:  goncurses.A_BOLD,
+		Prompt: goncurses.A_UNDERLINE,
+		Fill:   goncurses.A_REVERSE,
+		Match:  goncurses.A_BOLD,
+	}
+)
+
+/* Place rect of given size at center of parent, size limited by parent */
+func Center(parent Rect, sizeY, sizeX int) Rect {
+	sizeY, sizeX = min(sizeY, parent.SizeY), min(sizeX, parent.SizeX)
+	return Rect{sizeY, sizeX, parent.PosY + (parent.SizeY-sizeY)/2, parent.PosX + (parent.SizeX-sizeX)/2}
+}
+
+/* Cut text to width in runes */
+func Clip(text string, width int) string {
+	runes := []rune(text)
+
+	if width <= 0 {
+		return ""
+	}
+
+	return string(runes[:min(len(runes), width)])
+}
+
+/* Print text with attribute */
+func Print(win *goncurses.Window, y, x int, text string, attr goncurses.Char) {
+	win.AttrOn(attr)
+	win.MovePrint(y, x, text)
+	win.AttrOff(attr)
+}
+
+/* Print text clipped to width with attribute, rest of width filled by spaces */
+func PrintLine(win *goncurses.Window, y, x, width int, text string, attr goncurses.Char) {
+	line := []rune(Clip(text, width))
+
+	for len(line) < width {
+		line = append(line, ' ')
+	}
+
+	win.AttrOn(attr)
+	win.MovePrint(y, x, string(line))
+	win.AttrOff(attr)
+}
+
+/*
+Check is key a byte of multi-byte UTF-8 char
+ncurses returns such chars by one byte per key, widget which edits text collects them
+*/
+func IsTextByte(key goncurses.Key) bool {
+	return key >= 0x80 && key <= 0xff
+}
+
+/*
+Collects chars of text from keys, printable ASCII key is char itself,
+bytes of multi-byte UTF-8 char are kept until char is complete
+*/
+type TextDecoder struct {
+	pending []byte
+}
+
+/* Return char and true if key completes char, false for keys which are not text or incomplete char */
+func (self *TextDecoder) Decode(key goncurses.Key) (rune, bool) {
+	if key >= ' ' && key < 127 {
+		self.Reset()
+		return rune(key), true
+	}
+
+	if !IsTextByte(key) {
+		return 0, false
+	}
+
+	self.pending = append(self.pending, byte(key))
+
+	if !utf8.FullRune(self.pending) {
+		return 0, false
+	}
+
+	char, _ := utf8.DecodeRune(self.pending)
+	self.Reset()
+
+	return char, char != utf8.RuneError
+}
+
+/* Forget bytes of incomplete char, when input moves to other text */
+func (self *TextDecoder) Reset() {
+	self.pending = self.pending[:0]
+}
+
+/* Check is click opens item: click on already selected item or double click */
+func IsOpenClick(clicks int, selected bool) bool {
+	return clicks > 1 || clicks == 1 && selected
+}
+
+/* Length of text in runes */
+func Width(text string) int {
+	return len([]rune(text))
+}
+
+/* Clamp first visible row, so row with cursor is visible and no empty space left at end */
+func scrollTo(top, cursor, count, height int) int {
+	if cursor >= 0 {
+		top = min(top, cursor)
+		top = max(top, cursor-height+1)
+	}
+
+	return max(min(top, count-height), 0)
+}
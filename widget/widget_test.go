@@ -0,0 +1,96 @@
+package widget
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gbin/goncurses"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  []string
+	}{
+		{"empty", "", 5, []string{""}},
+		{"fits", "hello", 5, []string{"hello"}},
+		{"zero width", "hello world", 0, []string{"hello world"}},
+		{"break by space", "hello world", 5, []string{"hello", "world"}},
+		{"several spaces", "one  two   three", 8, []string{"one  two", "three"}},
+		{"long word", "abcdefgh", 3, []string{"abc", "def", "gh"}},
+		{"indent kept", "  indented text", 10, []string{"  indented", "text"}},
+		{"runes", "ОФЗ облигация", 3, []string{"ОФЗ", "обл", "ига", "ция"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Wrap(test.line, test.width)
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", test.line, test.width, got, test.want)
+			}
+		})
+	}
+}
+
+func TestScrollTo(t *testing.T) {
+	tests := []struct {
+		name                       string
+		top, cursor, count, height int
+		want                       int
+	}{
+		{"cursor visible", 0, 2, 10, 5, 0},
+		{"cursor below", 0, 7, 10, 5, 3},
+		{"cursor above", 5, 2, 10, 5, 2},
+		{"cursor at last row", 0, 9, 10, 5, 5},
+		{"no cursor keeps top", 3, -1, 10, 5, 3},
+		{"no cursor clamped at end", 8, -1, 10, 5, 5},
+		{"no cursor negative top", -2, -1, 10, 5, 0},
+		{"shorter than height", 3, 1, 2, 5, 0},
+		{"empty", 0, -1, 0, 5, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := scrollTo(test.top, test.cursor, test.count, test.height)
+
+			if got != test.want {
+				t.Errorf("scrollTo(%d, %d, %d, %d) = %d, want %d", test.top, test.cursor, test.count, test.height, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTextDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []goncurses.Key
+		want string
+	}{
+		{"ascii", []goncurses.Key{'o', 'f', 'z'}, "ofz"},
+		{"cyrillic", []goncurses.Key{0xd0, 0x9e, 0xd0, 0xa4, 0xd0, 0x97}, "ОФЗ"},
+		{"mixed", []goncurses.Key{'#', 0xd0, 0x9e, '1'}, "#О1"},
+		{"not text", []goncurses.Key{goncurses.KEY_UP, 'a', 27, 'b'}, "ab"},
+		{"broken sequence dropped", []goncurses.Key{0x9e, 'a'}, "a"},
+		{"incomplete sequence reset", []goncurses.Key{0xd0, 'a', 0xd0, 0x9e}, "aО"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var text TextDecoder
+			var got []rune
+
+			for _, key := range test.keys {
+				if char, isText := text.Decode(key); isText {
+					got = append(got, char)
+				}
+			}
+
+			if string(got) != test.want {
+				t.Errorf("decoded %q, want %q", string(got), test.want)
+			}
+		})
+	}
+}
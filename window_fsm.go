@@ -2,13 +2,13 @@
 A wrap for ncurses window
 Register function:
     CustomDraw - for draw anything
-    SpecialInputFunc - function binds with key, for processing this input key
+    SpecialInputFunc - function binds with key or action of keymap, for processing this input key
 Register a next windows:
     Same struct *FSMWindow binds with ncurses key. When you call FSMWindow.Input() you will recieve same *FSMWindow or next if pressed a special key for switch them
 
 Recomended order of functions:
     .Draw() - for clear and draw your data
-    .DrawBox() - for draw a box and title and refresh window
+    .DrawBox() - for draw a box and title and refresh window virtually, call goncurses.Update after all windows
     .Input() - can block if ncurses.timeout not setted
         Also return a bool from SpecialInputFunc, which you can use as you want
 */
@@ -18,16 +18,22 @@ import (
 	"github.com/gbin/goncurses"
 )
 
-type SpecialInputFunc func() bool // Custom func for process input, returning false mean stop to work and exit
-type CustomDraw func()            // Custom func from draw anything
+type SpecialInputFunc func() bool              // Custom func for process input, returning false mean stop to work and exit
+type CustomDraw func()                         // Custom func from draw anything
+type KeyInputFunc func(key goncurses.Key) bool // Custom func for process keys without own binding
 
 type FSMWindow struct {
 	Window       *goncurses.Window
 	SizeY, SizeX int
 	PosY, posX   int
 	Title        string
+	Hidden       bool // Window is not placed by layout, set by layout
+	Focused      bool // Window gets input, box drawn by focus color, set by navigator
+	Persistent   bool // Content is not erased before draw, window printed by itself
 
 	drawFunc     CustomDraw
+	mouseFunc    MouseInputFunc
+	defaultInput KeyInputFunc
 	nextWindow   map[goncurses.Key]*FSMWindow
 	specialInput map[goncurses.Key]SpecialInputFunc
 }
@@ -51,10 +57,39 @@ func FSMWindowNew(sizeY, sizeX, posY, posX int) (*FSMWindow, error) {
 	return obj, nil
 }
 
+/* Wrap existing ncurses window, content of it is kept between draws */
+func FSMWindowWrap(window *goncurses.Window) *FSMWindow {
+	obj := new(FSMWindow)
+	obj.Window = window
+	obj.nextWindow = make(map[goncurses.Key]*FSMWindow)
+	obj.specialInput = make(map[goncurses.Key]SpecialInputFunc)
+	obj.SizeY, obj.SizeX = window.MaxYX()
+	obj.PosY, obj.posX = window.YX()
+	obj.Persistent = true
+
+	return obj
+}
+
 func (self *FSMWindow) FreeWindow() {
 	self.Window.Delete()
 }
 
+/* Change size and position of window, content must be redrawn */
+func (self *FSMWindow) Resize(sizeY, sizeX, posY, posX int) *FSMWindow {
+	ResizeWindow(self.Window, Rect{sizeY, sizeX, posY, posX})
+	self.SizeX = sizeX
+	self.SizeY = sizeY
+	self.posX = posX
+	self.PosY = posY
+
+	return self
+}
+
+/* Size and position of window on screen */
+func (self *FSMWindow) Rect() Rect {
+	return Rect{self.SizeY, self.SizeX, self.PosY, self.posX}
+}
+
 func (self *FSMWindow) SetTitle(title string) *FSMWindow {
 	self.Title = title
 	return self
@@ -76,10 +111,41 @@ func (self *FSMWindow) RegisterInput(key goncurses.Key, function SpecialInputFun
 	return self
 }
 
+/* Bind function for processing keys, which are not bound by RegisterInput or RegisterNextWindow */
+func (self *FSMWindow) RegisterDefaultInput(function KeyInputFunc) *FSMWindow {
+	self.defaultInput = function
+	return self
+}
+
+/* Bind function for processing mouse events inside of window */
+func (self *FSMWindow) RegisterMouse(function MouseInputFunc) *FSMWindow {
+	self.mouseFunc = function
+	return self
+}
+
+/* Same as RegisterNextWindow for all keys of action in ActiveKeymap */
+func (self *FSMWindow) RegisterNextWindowAction(action string, window *FSMWindow) *FSMWindow {
+	for _, key := range ActiveKeymap.Keys(action) {
+		self.RegisterNextWindow(key, window)
+	}
 
-/* Call clear and then custom draw function. Call Draw before DrawBox */
+	return self
+}
+
+/* Same as RegisterInput for all keys of action in ActiveKeymap */
+func (self *FSMWindow) RegisterAction(action string, function SpecialInputFunc) *FSMWindow {
+	for _, key := range ActiveKeymap.Keys(action) {
+		self.RegisterInput(key, function)
+	}
+
+	return self
+}
+
+/* Call erase and then custom draw function. Call Draw before DrawBox */
 func (self *FSMWindow) Draw() *FSMWindow {
-	self.Window.Clear()
+	if !self.Persistent {
+		self.Window.Erase()
+	}
 
 	if self.drawFunc != nil {
 		self.drawFunc()
@@ -90,13 +156,17 @@ func (self *FSMWindow) Draw() *FSMWindow {
 
 /* Draw box and title if setted */
 func (self *FSMWindow) DrawBox() *FSMWindow {
-	self.Window.Box(0, 0)
+	if self.Focused {
+		ActiveTheme.BoxColor(self.Window, ActiveTheme.Focus)
+	} else {
+		ActiveTheme.Box(self.Window)
+	}
 
 	if self.Title != "" {
-		self.Window.MovePrint(0, self.SizeX/2-(len(self.Title)/2), self.Title)
+		ActiveTheme.Title.Print(self.Window, 0, self.SizeX/2-(len(self.Title)/2), self.Title)
 	}
 
-	self.Window.Refresh()
+	self.Window.NoutRefresh()
 	return self
 }
 
@@ -107,7 +177,11 @@ func (self *FSMWindow) DrawBox() *FSMWindow {
 Return next window or same and bool status from custom input-proccessing function
 */
 func (self *FSMWindow) Input() (*FSMWindow, bool) {
-	inputKey := self.Window.GetChar()
+	return self.InputKey(self.Window.GetChar())
+}
+
+/* Same as Input, but key is already read */
+func (self *FSMWindow) InputKey(inputKey goncurses.Key) (*FSMWindow, bool) {
 	var status bool = true
 	nextWin, exist := self.nextWindow[inputKey]
 
@@ -120,7 +194,23 @@ func (self *FSMWindow) Input() (*FSMWindow, bool) {
 
 	if exist {
 		status = inputFunc()
+	} else if self.defaultInput != nil {
+		status = self.defaultInput(inputKey)
 	}
 
 	return self, status
 }
+
+/* Check is point of screen inside of window */
+func (self *FSMWindow) Encloses(y, x int) bool {
+	return y >= self.PosY && y < self.PosY+self.SizeY && x >= self.posX && x < self.posX+self.SizeX
+}
+
+/* Pass mouse event into registered function, events outside of window are ignored */
+func (self *FSMWindow) InputMouse(event *goncurses.MouseEvent) bool {
+	if self.mouseFunc == nil || !self.Encloses(event.Y, event.X) {
+		return true
+	}
+
+	return self.mouseFunc(event.Y-self.PosY, event.X-self.posX, event.State)
+}